@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	"git.vepay.dev/knoknok/backend-platform/pkg/buildinfo"
+	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+const adminHealthTimeout = 5 * time.Second
+
+var (
+	adminComponent = NewComponent(ComponentAdmin, Noop, runAdmin)
+)
+
+// WithAdmin добавляет отдельный служебный http-сервер на admin.addr (по умолчанию :9092):
+// pprof, состояние компонентов, DI, closers, health checks и информация о сборке.
+// Порт не должен быть доступен снаружи кластера
+func WithAdmin() Option {
+	return func(app *Application) error {
+		app.components.add(component(adminComponent))
+		return nil
+	}
+}
+
+// Components возвращает состояние стадий init и run всех компонентов
+func (a *Application) Components() []ComponentStatus {
+	return a.components.statuses()
+}
+
+func runAdmin(ctx context.Context, app *Application) error {
+	if app.testMode {
+		// как и основной http-сервер, в тестах порт не слушается
+		return nil
+	}
+	addr := app.config.GetAdminAddr()
+	logger.Info(ctx, "Admin endpoint starting", logger.String("addr", addr))
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: app.adminHandler(),
+	}
+
+	app.Closer.Add(ComponentAdmin, server.Shutdown, closers.WithPhase(closers.PhaseTelemetry))
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.Fail(ComponentAdmin, err)
+		}
+	}()
+
+	return nil
+}
+
+func (a *Application) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/components", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, a.Components())
+	})
+	mux.HandleFunc("/debug/di", func(w http.ResponseWriter, r *http.Request) {
+		services := []di.Service{}
+		if a.container != nil {
+			services = a.container.Services()
+		}
+		writeJSON(w, services)
+	})
+	mux.HandleFunc("/debug/closers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, a.Closer.List())
+	})
+	mux.HandleFunc("/debug/health", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), adminHealthTimeout)
+		defer cancel()
+		writeJSON(w, a.Health.Report(ctx))
+	})
+	mux.HandleFunc("/debug/build", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, buildinfo.Get())
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
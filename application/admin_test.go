@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getJSON(t *testing.T, handler http.Handler, path string, v any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+}
+
+func TestAdminHandler(t *testing.T) {
+	app := &Application{
+		Closer:     closers.New(),
+		Health:     health.New(),
+		components: newComponents(),
+	}
+	app.components.add(component(NewComponent("ok", Noop, Noop)))
+	app.components.add(component(NewComponent("broken", func(context.Context, *Application) error {
+		return errors.New("boom")
+	}, Noop)))
+	_ = app.components.init(context.Background(), app)
+
+	app.Closer.Add("db", func(context.Context) error { return nil }, closers.WithPhase(closers.PhaseStorages))
+	app.Closer.Add("http", func(context.Context) error { return nil }, closers.WithPhase(closers.PhaseServers))
+	app.Health.Add("redis", func(context.Context) error { return errors.New("unavailable") })
+
+	handler := app.adminHandler()
+
+	var components []ComponentStatus
+	getJSON(t, handler, "/debug/components", &components)
+	require.Len(t, components, 2)
+	assert.Equal(t, StageDone, components[0].Init.State)
+	assert.Equal(t, StageFailed, components[1].Init.State)
+	assert.Equal(t, "boom", components[1].Init.Error)
+
+	var list []closers.Info
+	getJSON(t, handler, "/debug/closers", &list)
+	require.Len(t, list, 2)
+	assert.Equal(t, "http", list[0].Name)
+	assert.Equal(t, "db", list[1].Name)
+
+	var report health.Report
+	getJSON(t, handler, "/debug/health", &report)
+	assert.Equal(t, health.StatusDown, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "unavailable", report.Checks[0].Error)
+	assert.True(t, report.Checks[0].Critical)
+
+	var services []any
+	getJSON(t, handler, "/debug/di", &services)
+	assert.Empty(t, services)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRunAdminTestMode(t *testing.T) {
+	app := &Application{Closer: closers.New(), components: newComponents(), testMode: true}
+
+	// в тестах порт не занимается, закрывать нечего
+	require.NoError(t, runAdmin(context.Background(), app))
+	assert.Empty(t, app.Closer.List())
+}
@@ -2,11 +2,15 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
 	"git.vepay.dev/knoknok/backend-platform/pkg/swagger"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -17,13 +21,18 @@ import (
 	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
 	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
 	"git.vepay.dev/knoknok/backend-platform/internal/pkg/translations"
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
 	cfg "git.vepay.dev/knoknok/backend-platform/pkg/config"
 	"git.vepay.dev/knoknok/backend-platform/pkg/db"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/flags"
 	grpcclient "git.vepay.dev/knoknok/backend-platform/pkg/grpc/client"
+	"git.vepay.dev/knoknok/backend-platform/pkg/httproute"
+	"git.vepay.dev/knoknok/backend-platform/pkg/idempotency"
 	"git.vepay.dev/knoknok/backend-platform/pkg/kafka"
 	"git.vepay.dev/knoknok/backend-platform/pkg/localize"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/ratelimit"
 	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
 	"git.vepay.dev/knoknok/backend-platform/pkg/s3client"
 )
@@ -32,10 +41,15 @@ const (
 	defaultWaitCloserTime = time.Second * 30
 )
 
+var (
+	ErrComponentFailed = errors.New("component failed")
+)
+
 type Application struct {
 	Name      string
 	Closer    closers.Closer
 	Health    health.Health
+	Readiness health.Health // условия готовности компонентов, пока не выполнены - трафик не подается
 	Env       config.Configurer
 	config    appConfig
 	testMode  bool
@@ -45,13 +59,19 @@ type Application struct {
 	// private
 
 	started        chan struct{} // флаг-сигнал приложение успешно стартовало
+	startupDone    atomic.Bool   // после старта все условия готовности выполнились хотя бы раз
 	closed         chan struct{} // флаг сигнал процесс gracefull shutdown завершен
 	closing        chan struct{} // флаг-сигнал начался процесс gracefull shutdown
 	shutdown       chan os.Signal
+	failed         chan struct{} // флаг-сигнал компонент упал во время работы
+	failure        error         // первая фатальная ошибка компонента
+	failOnce       sync.Once
 	context        context.Context
 	components     *components
 	middlewares    middleware.Middlewares
+	leader         *elector
 	waitCloserTime time.Duration // wait closers time
+	drainDelay     time.Duration // пауза между снятием readiness и остановкой компонентов
 
 	// initializing components
 	Redis            redis.Redis
@@ -59,8 +79,13 @@ type Application struct {
 	Kafka            kafka.KafkaClient
 	Workflow         workflow.WorkflowBuilder
 	S3               s3client.Client
+	Flags            flags.Flags
 	router           http.Handler
 	httpServer       *http.Server
+	listeners        []*HTTPListener
+	rateLimitRules   []ratelimit.Rule
+	rateLimiter      *ratelimit.Limiter
+	routes           *httproute.Limiter // лимит меток path http-метрик
 	Localizer        localize.Localizer
 	translateManager translations.TranslateManager
 
@@ -68,8 +93,16 @@ type Application struct {
 	PrivateGrpcServer *grpcserver.Manager
 	PublicGrpcServer  *grpcserver.Manager
 	GrpcClients       *grpcclient.Manager
+	grpcServiceChecks map[string][]string // проверки app.Health, от которых зависит статус сервиса, см. WithGrpcServiceHealth
+
+	swagger   *swagger.Manager
+	swaggerUI middleware.Middleware // ui и gateway, создается в init
 
-	swagger *swagger.Manager
+	idempotency        *idempotency.Store
+	idempotencyOptions []idempotency.Option
+
+	authRules    *auth.Rules
+	authVerifier *auth.Verifier
 }
 
 func NewWithConfig(ctx context.Context, env config.Configurer, components ...Option) (*Application, error) {
@@ -92,22 +125,42 @@ func New(ctx context.Context, components ...Option) (*Application, error) {
 }
 
 func new(ctx context.Context, env config.Configurer, components ...Option) (*Application, error) {
+	appCfg := appConfig{env}
+	shutdownCfg := appCfg.GetShutdownConfig()
+
 	app := &Application{
 		Closer:         closers.New(),
-		Health:         health.New(),
+		Health:         newHealth(appCfg),
+		Readiness:      health.New(health.WithProbe("readiness")),
 		middlewares:    middleware.New(),
 		components:     newComponents(),
+		leader:         newElector(),
 		started:        make(chan struct{}, 1),
 		closed:         make(chan struct{}, 1),
 		closing:        make(chan struct{}, 1),
 		shutdown:       make(chan os.Signal, 1),
+		failed:         make(chan struct{}),
 		Env:            env,
-		config:         appConfig{env},
+		config:         appCfg,
 		context:        ctx,
 		router:         http.HandlerFunc(noopHandler()),
-		waitCloserTime: defaultWaitCloserTime,
+		waitCloserTime: shutdownCfg.Timeout,
+		drainDelay:     shutdownCfg.DrainDelay,
+		routes:         httproute.NewLimiter(appCfg.GetHTTPMiddlewaresConfig().MaxRoutes),
 	}
 
+	for phase, timeout := range shutdownCfg.PhaseTimeouts {
+		app.Closer.SetPhaseTimeout(phase, timeout)
+	}
+
+	// фоновый опрос health check с app.healthz.interval, останавливается вместе с серверами
+	healthCtx, stopHealth := context.WithCancel(context.WithoutCancel(ctx))
+	app.Health.Start(healthCtx)
+	app.Closer.Add("health", func(context.Context) error {
+		stopHealth()
+		return nil
+	}, closers.WithPhase(closers.PhaseServers))
+
 	// добавляем компонент контейнера первым,
 	// на этапе init создается контейнер
 	// на этапе run запускается создание фабрик и инжектирование зависимостей
@@ -119,14 +172,28 @@ func new(ctx context.Context, env config.Configurer, components ...Option) (*App
 		}
 	}
 
+	// секция components включает и выключает компоненты без пересборки
+	if err := app.applyComponentsConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := app.validateComponents(); err != nil {
+		logger.Error(ctx, "Application components misconfigured", logger.Err(err))
+		return nil, err
+	}
+
+	// мидлвари по умолчанию: request id, метрики, перехват паники
+	app.addDefaultMiddlewares(app.middlewares)
+
 	// добавление k8s мидлваров
 	app.addProbes()
 
+	// мидлвари auth, ratelimit, idempotency, swagger после проб
+	app.addComponentMiddlewares(app.middlewares)
+	app.addComponentInterceptors()
+
 	// подписка на изменения и регистрация в di
 	app.initConfig(ctx)
 
-	// TODO добавить мидлвари для отлов паники
-
 	logger.Info(ctx, "Application components initializing")
 	if err := app.components.init(ctx, app); err != nil {
 		logger.Error(ctx, "Application components failed", logger.Err(err))
@@ -135,17 +202,32 @@ func new(ctx context.Context, env config.Configurer, components ...Option) (*App
 
 	logger.Info(ctx, "Application created")
 
-	signal.Notify(app.shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	if !app.testMode {
+		signal.Notify(app.shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	}
 	go app.finish()
 
 	return app, nil
 }
 
+// finish graceful shutdown:
+// снятие readiness, пауза на исключение пода из балансировки,
+// затем closers по фазам: остановка приема трафика, компоненты, хранилища, телеметрия
 func (a *Application) finish() {
-	signal := <-a.shutdown
-	logger.Info(a.context, "Application shutdown started", logger.String("signal", signal.String()))
+	select {
+	case signal := <-a.shutdown:
+		logger.Info(a.context, "Application shutdown started", logger.String("signal", signal.String()))
+	case <-a.failed:
+		logger.Error(a.context, "Application shutdown started by component failure", logger.Err(a.failure))
+	}
 
 	close(a.closing)
+
+	if a.drainDelay > 0 {
+		logger.Info(a.context, "Application draining traffic", logger.Duration("drain_delay", a.drainDelay))
+		time.Sleep(a.drainDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(a.context, a.waitCloserTime)
 	defer cancel()
 	if err := a.Closer.Close(ctx); err != nil {
@@ -160,20 +242,67 @@ func (a *Application) stop() {
 	a.shutdown <- os.Interrupt
 }
 
+// Shutdown запускает graceful shutdown как при SIGTERM и ждет завершения closers
+func (a *Application) Shutdown(ctx context.Context) error {
+	select {
+	case a.shutdown <- os.Interrupt:
+	default: // остановка уже запущена
+	}
+
+	select {
+	case <-a.closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Started закрывается после успешного запуска всех компонентов в Run
+func (a *Application) Started() <-chan struct{} {
+	return a.started
+}
+
+// Fail сообщает о фатальной ошибке компонента во время работы,
+// приложение останавливается, а Run возвращает первую такую ошибку
+func (a *Application) Fail(component string, err error) {
+	a.failOnce.Do(func() {
+		logger.Error(a.context, "Application component failed",
+			logger.String("component", component),
+			logger.Err(err),
+		)
+		a.failure = fmt.Errorf("%w: %s: %w", ErrComponentFailed, component, err)
+		close(a.failed)
+	})
+}
+
 func (a *Application) Run() error {
 	ctx, cancel := context.WithCancel(a.context)
 	defer cancel()
 
 	if err := a.components.run(ctx, a); err != nil {
 		logger.Error(ctx, "Application finished with error", logger.Err(err))
+		// компоненты запускаются параллельно, успевшие стартовать останавливаются closers
+		select {
+		case a.shutdown <- os.Interrupt:
+		default: // остановка уже запущена
+		}
+		<-a.closed
 		return err
 	}
 
 	logger.Info(a.context, "Application running")
 	close(a.started)
+	go a.syncGrpcHealth(ctx)
 
 	<-a.closed
 
+	select {
+	case <-a.failed:
+		logger.Error(a.context, "Application exit with error", logger.Err(a.failure))
+		return a.failure
+	default:
+	}
+
 	logger.Info(a.context, "Application exit")
 	return nil
 }
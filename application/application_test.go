@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -232,6 +233,48 @@ func TestCheckReadiness(t *testing.T) {
 	}
 }
 
+func TestCheckStartup(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	err := config.Init(ctx, config.WithConfigPath("./"), config.WithFileName("test.env"))
+	assert.NoError(t, err)
+
+	app, err := newApp(ctx, config.GetConfig())
+	require.NoError(t, err)
+
+	var joined atomic.Bool
+	app.Readiness.Add("consumers", func(context.Context) error {
+		if !joined.Load() {
+			return errors.New("not joined")
+		}
+		return nil
+	})
+
+	res := checkStartup(ctx, app)
+	assert.Equal(t, "starting", res.Status, res)
+
+	waitRun(t, ctx, app)
+
+	// приложение запущено, но условие готовности не выполнено
+	res = checkStartup(ctx, app)
+	assert.Equal(t, "starting", res.Status, res)
+	res = checkReadiness(ctx, app)
+	assert.Equal(t, "not_ready", res.Status, res)
+	assert.Contains(t, res.Message, "consumers")
+
+	joined.Store(true)
+	assert.Equal(t, "started", checkStartup(ctx, app).Status)
+	assert.Equal(t, "ready", checkReadiness(ctx, app).Status)
+
+	// startup-проба не возвращается в starting, готовность снимает readiness
+	joined.Store(false)
+	assert.Equal(t, "started", checkStartup(ctx, app).Status)
+	assert.Equal(t, "not_ready", checkReadiness(ctx, app).Status)
+
+	app.stop()
+}
+
 func TestClosers(t *testing.T) {
 	ctx := context.Background()
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -245,9 +288,7 @@ func TestClosers(t *testing.T) {
 	app, err := NewWithConfig(ctx, config.GetConfig())
 	assert.NoError(t, err)
 
-	app.Closer.Add(func() error {
-		return mock.Close(context.TODO())
-	})
+	app.Closer.Add("test", mock.Close)
 
 	mock.EXPECT().Close(gomock.Any()).Return(nil)
 
@@ -275,9 +316,9 @@ func TestClosersLong(t *testing.T) {
 	app, err := newApp(ctx, config.GetConfig())
 	assert.NoError(t, err)
 
-	app.Closer.Add(func() error {
+	app.Closer.Add("test", func(ctx context.Context) error {
 		time.Sleep(time.Second * 10)
-		return mock.Close(context.TODO())
+		return mock.Close(ctx)
 	})
 
 	mock.EXPECT().Close(gomock.Any()).Times(0)
@@ -294,6 +335,75 @@ func TestClosersLong(t *testing.T) {
 	}
 }
 
+func TestRunReturnsComponentFailure(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err := config.Init(ctx, config.WithConfigPath("./"), config.WithFileName("test.env"))
+	assert.NoError(t, err)
+
+	errListen := errors.New("listen failed")
+	app, err := newApp(ctx, config.GetConfig(), WithComponent(
+		"test",
+		Noop,
+		func(ctx context.Context, a *Application) error {
+			go a.Fail("test", errListen)
+			return nil
+		},
+	))
+	assert.NoError(t, err)
+
+	res := make(chan error, 1)
+	go func() {
+		res <- app.Run()
+	}()
+
+	select {
+	case err := <-res:
+		assert.ErrorIs(t, err, ErrComponentFailed)
+		assert.ErrorIs(t, err, errListen)
+		assert.ErrorContains(t, err, "test")
+	case <-ctx.Done():
+		assert.FailNow(t, "test timeout")
+	}
+}
+
+func TestRunStopsStartedComponentsOnFailure(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err := config.Init(ctx, config.WithConfigPath("./"), config.WithFileName("test.env"))
+	assert.NoError(t, err)
+
+	errRun := errors.New("run failed")
+	var stopped atomic.Bool
+	app, err := newApp(ctx, config.GetConfig(),
+		WithComponent("consumer", Noop, func(ctx context.Context, a *Application) error {
+			a.Closer.Add("consumer", func(context.Context) error {
+				stopped.Store(true)
+				return nil
+			})
+			return nil
+		}),
+		WithComponent("broken", Noop, func(context.Context, *Application) error { return errRun }),
+	)
+	assert.NoError(t, err)
+
+	res := make(chan error, 1)
+	go func() {
+		res <- app.Run()
+	}()
+
+	// Run возвращает ошибку после остановки уже запущенных компонентов
+	select {
+	case err := <-res:
+		assert.ErrorIs(t, err, errRun)
+		assert.True(t, stopped.Load())
+	case <-ctx.Done():
+		assert.FailNow(t, "test timeout")
+	}
+}
+
 func waitRun(t *testing.T, ctx context.Context, app *Application) {
 	go func() {
 		app.Run()
@@ -0,0 +1,200 @@
+// applicationtest сборка Application для интеграционных тестов с in-memory заменами инфраструктуры
+package applicationtest
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/application"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	configprovider "git.vepay.dev/knoknok/backend-platform/internal/pkg/config_provider"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/config_provider/memory"
+	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/kafka"
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"git.vepay.dev/knoknok/backend-platform/pkg/s3client"
+)
+
+const (
+	startTimeout    = 10 * time.Second
+	shutdownTimeout = 10 * time.Second
+)
+
+// Harness запущенное приложение и его in-memory зависимости
+type Harness struct {
+	App    *application.Application
+	Server *httptest.Server // обслуживает роутер приложения вместе с мидлварями
+
+	Redis *redis.Memory          // заполнен при WithRedis
+	Kafka *kafka.MemoryClient    // заполнен при WithKafka
+	S3    *s3client.MemoryClient // заполнен при WithS3
+
+	provider configprovider.Provider
+}
+
+type options struct {
+	config     map[string]any
+	substitute []application.Option
+	app        []application.Option
+}
+
+type Option func(*options, *Harness)
+
+// WithConfig значения конфига, ключи в формате "app.name", перекрывают значения по умолчанию
+func WithConfig(values map[string]any) Option {
+	return func(o *options, _ *Harness) {
+		maps.Copy(o.config, values)
+	}
+}
+
+// WithOptions опции приложения, те же что в main. Реальные WithRedis, WithKafka, WithS3
+// игнорируются, если подключена соответствующая in-memory замена
+func WithOptions(opts ...application.Option) Option {
+	return func(o *options, _ *Harness) {
+		o.app = append(o.app, opts...)
+	}
+}
+
+// WithRedis in-memory redis вместо компонента redis, доступен через h.Redis, app.Redis и di
+func WithRedis() Option {
+	return func(o *options, h *Harness) {
+		h.Redis = redis.NewMemory()
+		o.substitute = append(o.substitute, application.WithComponent(application.ComponentRedis,
+			func(ctx context.Context, app *application.Application) error {
+				app.Redis = h.Redis
+				di.Register(ctx, app.Redis)
+				return nil
+			}, application.Noop, application.ComponentDI))
+	}
+}
+
+// WithKafka in-memory kafka вместо компонента kafka, доступен через h.Kafka, app.Kafka и di
+func WithKafka() Option {
+	return func(o *options, h *Harness) {
+		h.Kafka = kafka.NewMemoryClient()
+		o.substitute = append(o.substitute, application.WithComponent(application.ComponentKafka,
+			func(ctx context.Context, app *application.Application) error {
+				app.Kafka = h.Kafka
+				di.Register(ctx, app.Kafka)
+				return nil
+			},
+			func(ctx context.Context, app *application.Application) error {
+				return app.Kafka.Run(ctx)
+			}, application.ComponentDI))
+	}
+}
+
+// WithS3 in-memory s3 вместо компонента s3, доступен через h.S3, app.S3 и di
+func WithS3() Option {
+	return func(o *options, h *Harness) {
+		h.S3 = s3client.NewMemoryClient()
+		o.substitute = append(o.substitute, application.WithComponent(application.ComponentS3,
+			func(ctx context.Context, app *application.Application) error {
+				app.S3 = h.S3
+				di.Register(ctx, app.S3)
+				return nil
+			}, application.Noop, application.ComponentDI))
+	}
+}
+
+// New собирает и запускает приложение, остановка регистрируется в t.Cleanup
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+
+	h := &Harness{}
+	o := &options{
+		config: map[string]any{
+			"app.name":             "test",
+			"app.shutdown.timeout": shutdownTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(o, h)
+	}
+
+	env := config.New("", "")
+	if err := env.LoadEnvMap(expand(o.config)); err != nil {
+		t.Fatalf("applicationtest: config: %v", err)
+	}
+	// изменения через SetConfig приходят подписчикам так же, как из consul
+	h.provider = memory.NewProvider(nil)
+	if err := env.LoadFromProvider(context.Background(), h.provider); err != nil {
+		t.Fatalf("applicationtest: config provider: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// замены добавляются первыми, одноименные реальные компоненты после них не регистрируются
+	appOpts := append([]application.Option{application.WithTestMode()}, o.substitute...)
+	app, err := application.NewWithConfig(ctx, env, append(appOpts, o.app...)...)
+	if err != nil {
+		t.Fatalf("applicationtest: new application: %v", err)
+	}
+	h.App = app
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	select {
+	case <-app.Started():
+	case err := <-done:
+		t.Fatalf("applicationtest: run application: %v", err)
+	case <-time.After(startTimeout):
+		t.Fatalf("applicationtest: application not started in %s", startTimeout)
+	}
+
+	// роутер может быть зарегистрирован после запуска, поэтому берется на каждый запрос
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		app.Handler().ServeHTTP(w, r)
+	}))
+
+	t.Cleanup(func() {
+		h.Server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := app.Shutdown(ctx); err != nil {
+			t.Errorf("applicationtest: shutdown: %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("applicationtest: application finished with error: %v", err)
+		}
+	})
+
+	return h
+}
+
+// SetConfig меняет значения конфига во время работы, подписчики (ConfigWatcher) обновляются асинхронно
+func (h *Harness) SetConfig(t testing.TB, values map[string]any) {
+	t.Helper()
+	if err := h.provider.Set(context.Background(), expand(values)); err != nil {
+		t.Fatalf("applicationtest: set config: %v", err)
+	}
+}
+
+// expand переводит ключи вида "app.name" во вложенные map, как в yaml
+func expand(values map[string]any) map[string]any {
+	res := make(map[string]any)
+	for key, value := range values {
+		parts := strings.Split(key, ".")
+		node := res
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := node[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				node[part] = next
+			}
+			node = next
+		}
+		node[parts[len(parts)-1]] = value
+	}
+	return res
+}
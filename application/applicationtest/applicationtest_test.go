@@ -0,0 +1,114 @@
+package applicationtest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/application"
+	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/kafka"
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHarnessHTTPWithRedis(t *testing.T) {
+	h := New(t,
+		WithRedis(),
+		WithOptions(application.WithRedis(), application.WithHTTP()),
+	)
+
+	ctx := context.Background()
+	require.NoError(t, h.Redis.Set(ctx, "greeting", "hello", time.Minute))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/greeting", func(w http.ResponseWriter, r *http.Request) {
+		var greeting string
+		if err := di.Resolve[redis.Redis](r.Context()).Get(r.Context(), "greeting").Scan(&greeting); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, greeting)
+	})
+	h.App.RegisterRouter(mux)
+
+	resp, err := http.Get(h.Server.URL + "/greeting")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(body))
+
+	// пробы добавляются мидлварями приложения
+	resp, err = http.Get(h.Server.URL + "/healthz/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHarnessIdempotencyWithRedis(t *testing.T) {
+	h := New(t,
+		WithRedis(),
+		WithOptions(application.WithRedis(), application.WithHTTP(), application.WithIdempotency()),
+	)
+
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/payments", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+	h.App.RegisterRouter(mux)
+
+	// повтор с тем же ключом получает сохраненный ответ, скрипты выполняются in-memory redis
+	for range 2 {
+		req, err := http.NewRequest(http.MethodPost, h.Server.URL+"/payments", nil)
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "k1")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestHarnessKafka(t *testing.T) {
+	received := make(chan string, 1)
+	h := New(t,
+		WithKafka(),
+		WithOptions(application.WithComponent("consumer",
+			func(ctx context.Context, app *application.Application) error {
+				return app.Kafka.RegisterConsumer(ctx, "events", func(ctx context.Context, msg kafka.Message) error {
+					received <- string(msg.Value)
+					return nil
+				})
+			}, application.Noop, application.ComponentKafka)),
+	)
+
+	producer, err := h.App.Kafka.RegisterProducer(context.Background(), "events")
+	require.NoError(t, err)
+	require.NoError(t, producer.Produce(context.Background(), kafka.ProduceMessage{Value: []byte("created")}))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "created", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message is not consumed")
+	}
+	assert.Len(t, h.Kafka.Messages("events"), 1)
+}
+
+func TestHarnessConfig(t *testing.T) {
+	h := New(t, WithConfig(map[string]any{"feature.limit": 5}))
+
+	assert.Equal(t, 5, h.App.Env.GetInt("feature.limit"))
+	assert.Equal(t, "test", h.App.Env.GetString("app.name"))
+
+	h.SetConfig(t, map[string]any{"feature.limit": 10})
+	assert.Equal(t, 10, h.App.Env.GetInt("feature.limit"))
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	cfg "git.vepay.dev/knoknok/backend-platform/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	envAuthJWKSURL         = "auth.jwks_url"
+	envAuthVaultPath       = "auth.vault_path"
+	envAuthRefreshInterval = "auth.refresh_interval"
+	envAuthIssuer          = "auth.issuer"
+	envAuthAudience        = "auth.audience"
+	envAuthLeeway          = "auth.leeway"
+	envAuthScopeClaim      = "auth.scope_claim"
+	envAuthTenantClaim     = "auth.tenant_claim"
+)
+
+var (
+	ErrAuthKeysNotConfigured = errors.New("auth.jwks_url or auth.vault_path is required")
+	ErrAuthVaultNotAvailable = errors.New("vault is disabled, auth.vault_path can not be used")
+)
+
+// WithAuth проверка JWT для основного http-сервера и gRPC серверов (публичного и приватного).
+// Ключи берутся с auth.jwks_url или из vault по auth.vault_path и обновляются без перезапуска.
+// Мидлварь и интерсепторы добавляются после всех опций и выполняются до ratelimit и idempotency,
+// поэтому их ключи могут использовать auth.Subject, а порядок опций не важен
+func WithAuth(rules *auth.Rules) Option {
+	return func(app *Application) error {
+		if rules == nil {
+			rules = auth.NewRules()
+		}
+		app.authRules = rules
+		app.components.add(component(NewComponent(ComponentAuth, initAuth, Noop).withCheck(checkAuth)))
+		return nil
+	}
+}
+
+func checkAuth(app *Application) error {
+	if app.Env.GetString(envAuthVaultPath) == "" && app.Env.GetString(envAuthJWKSURL) == "" {
+		return ErrAuthKeysNotConfigured
+	}
+	return nil
+}
+
+func initAuth(ctx context.Context, app *Application) error {
+	keys, err := app.authKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ключи обновляются до остановки приложения
+	refreshCtx, stopRefresh := context.WithCancel(context.WithoutCancel(ctx))
+	go keys.Run(refreshCtx)
+	app.Closer.Add(ComponentAuth, func(context.Context) error {
+		stopRefresh()
+		return nil
+	}, closers.WithPhase(closers.PhaseTelemetry))
+
+	app.authVerifier = auth.NewVerifier(keys, auth.Config{
+		Issuer:      app.Env.GetString(envAuthIssuer),
+		Audience:    app.Env.GetStringSlice(envAuthAudience),
+		Leeway:      app.Env.GetDuration(envAuthLeeway),
+		ScopeClaim:  app.Env.GetString(envAuthScopeClaim),
+		TenantClaim: app.Env.GetString(envAuthTenantClaim),
+	})
+	return nil
+}
+
+func (a *Application) authKeys(ctx context.Context) (*auth.CachedKeys, error) {
+	interval := a.Env.GetDuration(envAuthRefreshInterval)
+
+	if path := a.Env.GetString(envAuthVaultPath); path != "" {
+		client := cfg.GetVaultClient()
+		if client == nil {
+			return nil, ErrAuthVaultNotAvailable
+		}
+		logger.Info(ctx, "Auth keys from vault", logger.String("path", path))
+		return auth.NewVaultKeys(ctx, client, cfg.GetVaultMount(), path, interval)
+	}
+
+	url := a.Env.GetString(envAuthJWKSURL)
+	if url == "" {
+		return nil, ErrAuthKeysNotConfigured
+	}
+	logger.Info(ctx, "Auth keys from JWKS", logger.String("url", url))
+	return auth.NewJWKS(ctx, url, nil, interval)
+}
+
+// authMiddleware верификатор создается в init компонента, до этого запросы отклоняются.
+// Добавляется после проб, чтобы healthz не требовал токен
+func (a *Application) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.authVerifier == nil {
+			http.Error(w, "auth is not initialized", http.StatusServiceUnavailable)
+			return
+		}
+		auth.Middleware(a.authVerifier, a.authRules)(next)(w, r)
+	}
+}
+
+// authUnaryInterceptor верификатор создается в init компонента, до этого запросы отклоняются
+func (a *Application) authUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if a.authVerifier == nil {
+		return nil, status.Error(codes.Unavailable, "auth is not initialized")
+	}
+	return auth.UnaryServerInterceptor(a.authVerifier, a.authRules)(ctx, req, info, handler)
+}
+
+func (a *Application) authStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if a.authVerifier == nil {
+		return status.Error(codes.Unavailable, "auth is not initialized")
+	}
+	return auth.StreamServerInterceptor(a.authVerifier, a.authRules)(srv, ss, info, handler)
+}
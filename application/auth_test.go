@@ -0,0 +1,158 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	"git.vepay.dev/knoknok/backend-platform/pkg/ratelimit"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestAuth(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1"}}})
+	}))
+	defer jwks.Close()
+
+	newApp := func(values map[string]any) *Application {
+		env := cfg.New("", "")
+		require.NoError(t, env.LoadEnvMap(values))
+		return &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+			middlewares: middleware.New(), components: newComponents()}
+	}
+
+	app := newApp(map[string]any{})
+	require.NoError(t, WithAuth(nil)(app))
+	assert.ErrorIs(t, initAuth(context.Background(), app), ErrAuthKeysNotConfigured)
+
+	app = newApp(map[string]any{"auth": map[string]any{"jwks_url": jwks.URL}})
+	require.NoError(t, WithAuth(auth.NewRules().Route("/public/", auth.Public()))(app))
+	require.NoError(t, initAuth(context.Background(), app))
+	defer func() { _ = app.Closer.Close(context.Background()) }()
+	app.addComponentMiddlewares(app.middlewares)
+
+	handler := app.middlewares.Chain()(func(w http.ResponseWriter, r *http.Request) {})
+	call := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusUnauthorized, call("/payments"))
+	assert.Equal(t, http.StatusOK, call("/public/docs"))
+}
+
+func TestAuthMiddlewareOrder(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	// ключи отдаются с задержкой, init auth завершается последним
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1"}}})
+	}))
+	defer jwks.Close()
+
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{"auth": map[string]any{"jwks_url": jwks.URL}}))
+	app := &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+		middlewares: middleware.New(), components: newComponents()}
+	require.NoError(t, WithRateLimit(ratelimit.Rule{Name: "api", Key: ratelimit.ByIP(),
+		Limit: ratelimit.Limit{Rate: 1, Period: time.Minute}})(app))
+	require.NoError(t, WithAuth(nil)(app))
+	app.addComponentMiddlewares(app.middlewares)
+
+	require.NoError(t, app.components.init(context.Background(), app))
+	defer func() { _ = app.Closer.Close(context.Background()) }()
+
+	// auth выполняется до лимитов: запросы без токена отклоняются с 401 и не расходуют лимит
+	handler := app.middlewares.Chain()(func(w http.ResponseWriter, r *http.Request) {})
+	for range 3 {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/payments", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+}
+
+func TestAuthGrpcInterceptors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	app := newGrpcTestApp(t)
+	// WithAuth до WithPublicGrpcServer: интерсептор добавляется после всех опций
+	require.NoError(t, WithAuth(nil)(app))
+	require.NoError(t, WithPublicGrpcServer(registerTestPayments, &testPayments{})(app))
+	app.addComponentInterceptors()
+	conn := serveGrpc(ctx, t, app)
+
+	// верификатор создается в init, до этого вызов отклоняет auth
+	err := conn.Invoke(ctx, testPaymentsCreate, wrapperspb.String("1"), &wrapperspb.StringValue{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+const testPaymentsCreate = "/test.v1.Payments/Create"
+
+type testPayments struct{}
+
+func registerTestPayments(s grpc.ServiceRegistrar, srv *testPayments) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.v1.Payments",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Create",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := &wrapperspb.StringValue{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) { return req, nil }
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: testPaymentsCreate}, handler)
+			},
+		}},
+	}, srv)
+}
+
+// newGrpcTestApp приложение с публичным gRPC сервером на свободном порту
+func newGrpcTestApp(t *testing.T) *Application {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, l.Close())
+
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{
+		"grpc": map[string]any{"server": map[string]any{"public": map[string]any{"host": "127.0.0.1", "port": port}}},
+	}))
+	return &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+		middlewares: middleware.New(), components: newComponents()}
+}
+
+func serveGrpc(ctx context.Context, t *testing.T, app *Application) *grpc.ClientConn {
+	require.NoError(t, app.PublicGrpcServer.Initialize(ctx))
+	require.NoError(t, app.PublicGrpcServer.Start(ctx))
+	t.Cleanup(func() { _ = app.PublicGrpcServer.Stop() })
+
+	conn, err := grpc.NewClient(app.PublicGrpcServer.Addr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
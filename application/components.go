@@ -2,7 +2,43 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+// Названия встроенных компонентов, используются для объявления зависимостей
+const (
+	ComponentDI                = "di"
+	ComponentPostgres          = "postgres"
+	ComponentRedis             = "redis"
+	ComponentKafka             = "kafka"
+	ComponentS3                = "s3"
+	ComponentWorkflow          = "workflow"
+	ComponentLocalize          = "localize"
+	ComponentMetrics           = "metrics"
+	ComponentTrace             = "trace"
+	ComponentSwagger           = "swagger"
+	ComponentHTTP              = "http"
+	ComponentGrpcClient        = "grpc-client"
+	ComponentGrpcPrivateServer = "grpc-private-server"
+	ComponentGrpcPublicServer  = "grpc-public-server"
+	ComponentLeader            = "leader"
+	ComponentAdmin             = "admin"
+	ComponentRateLimit         = "ratelimit"
+	ComponentIdempotency       = "idempotency"
+	ComponentAuth              = "auth"
+	ComponentFlags             = "flags"
+)
+
+var (
+	ErrComponentCycle             = errors.New("component dependency cycle")
+	ErrComponentDependencyMissing = errors.New("component dependency not found")
 )
 
 type ComponentFunc func(context.Context, *Application) error
@@ -13,11 +49,43 @@ type component struct {
 	name   string
 	initFn ComponentFunc
 	runFn  ComponentFunc
+	deps   []string // названия компонентов, которые должны быть запущены раньше
+	// check проверка конфига до init, см. validateComponents
+	check func(*Application) error
 }
 
 type components struct {
 	order []string
 	list  map[string]component
+
+	mu     sync.RWMutex
+	status map[string]*ComponentStatus
+}
+
+// StageState состояние стадии init или run компонента
+type StageState string
+
+const (
+	StagePending StageState = "pending"
+	StageRunning StageState = "running"
+	StageDone    StageState = "done"
+	StageFailed  StageState = "failed"
+	StageSkipped StageState = "skipped" // упала зависимость
+)
+
+type StageStatus struct {
+	State     StageState    `json:"state"`
+	StartedAt time.Time     `json:"started_at,omitzero"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// ComponentStatus состояние компонента для диагностики
+type ComponentStatus struct {
+	Name string      `json:"name"`
+	Deps []string    `json:"deps,omitempty"`
+	Init StageStatus `json:"init"`
+	Run  StageStatus `json:"run"`
 }
 
 func Noop(context.Context, *Application) error {
@@ -26,7 +94,8 @@ func Noop(context.Context, *Application) error {
 
 func newComponents() *components {
 	return &components{
-		list: make(map[string]component, 0),
+		list:   make(map[string]component, 0),
+		status: make(map[string]*ComponentStatus, 0),
 	}
 }
 
@@ -39,46 +108,225 @@ func (e *components) add(ent component) bool {
 	if e.has(ent.name) {
 		return false
 	}
+	// init регистрирует зависимости в глобальном контейнере, а run получает их после Build,
+	// поэтому все компоненты неявно зависят от контейнера
+	if ent.name != ComponentDI && e.has(ComponentDI) && !slices.Contains(ent.deps, ComponentDI) {
+		ent.deps = append([]string{ComponentDI}, ent.deps...)
+	}
 	e.order = append(e.order, ent.name)
 	e.list[ent.name] = ent
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status[ent.name] = &ComponentStatus{
+		Name: ent.name,
+		Deps: ent.deps,
+		Init: StageStatus{State: StagePending},
+		Run:  StageStatus{State: StagePending},
+	}
 	return true
 }
 
-func (e *components) addFirst(ent component) bool {
-	if e.has(ent.name) {
-		return false
+// remove убирает компонент, выключенный в конфиге
+func (e *components) remove(name string) {
+	if !e.has(name) {
+		return
 	}
-	e.order = append([]string{ent.name}, e.order...)
-	e.list[ent.name] = ent
-	return true
+	e.order = slices.DeleteFunc(e.order, func(item string) bool { return item == name })
+	delete(e.list, name)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.status, name)
 }
 
-func (e *components) init(ctx context.Context, a *Application) error {
-	for _, key := range e.order {
-		item := e.list[key]
-		e := item.initFn(ctx, a)
-		if e != nil {
-			return fmt.Errorf("failed init %s component, error: %w", item.name, e)
+func (e *components) setStatus(name, stage string, status StageStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cs, ok := e.status[name]
+	if !ok {
+		return
+	}
+	if stage == "init" {
+		cs.Init = status
+	} else {
+		cs.Run = status
+	}
+}
+
+// statuses возвращает состояние компонентов в порядке добавления
+func (e *components) statuses() []ComponentStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	res := make([]ComponentStatus, 0, len(e.order))
+	for _, name := range e.order {
+		res = append(res, *e.status[name])
+	}
+	return res
+}
+
+// sorted возвращает компоненты в порядке зависимостей,
+// независимые компоненты сохраняют порядок добавления
+func (e *components) sorted() ([]component, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(e.order))
+	result := make([]component, 0, len(e.order))
+	path := make([]string, 0, len(e.order))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, name):], name)
+			return fmt.Errorf("%w: %s", ErrComponentCycle, strings.Join(cycle, " -> "))
+		}
+
+		item := e.list[name]
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dep := range item.deps {
+			if !e.has(dep) {
+				return fmt.Errorf("%w: %s required by %s", ErrComponentDependencyMissing, dep, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
 		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		result = append(result, item)
+		return nil
 	}
-	return nil
+
+	for _, name := range e.order {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e *components) init(ctx context.Context, a *Application) error {
+	return e.execute(ctx, a, "init", func(c component) ComponentFunc { return c.initFn })
 }
 
 func (e *components) run(ctx context.Context, a *Application) error {
-	for _, key := range e.order {
-		item := e.list[key]
-		e := item.runFn(ctx, a)
-		if e != nil {
-			return fmt.Errorf("failed run %s component, error: %w", item.name, e)
-		}
+	return e.execute(ctx, a, "run", func(c component) ComponentFunc { return c.runFn })
+}
+
+// execution результат выполнения стадии компонента,
+// err можно читать только после закрытия done
+type execution struct {
+	done chan struct{}
+	err  error
+}
+
+// execute выполняет стадию всех компонентов,
+// компонент стартует сразу после завершения своих зависимостей,
+// независимые компоненты выполняются параллельно
+func (e *components) execute(
+	ctx context.Context,
+	a *Application,
+	stage string,
+	fn func(component) ComponentFunc,
+) error {
+	items, err := e.sorted()
+	if err != nil {
+		return err
 	}
-	return nil
+
+	executions := make(map[string]*execution, len(items))
+	for _, item := range items {
+		executions[item.name] = &execution{done: make(chan struct{})}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+
+	for _, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			current := executions[item.name]
+			defer close(current.done)
+
+			for _, dep := range item.deps {
+				d := executions[dep]
+				<-d.done
+				if d.err != nil {
+					current.err = fmt.Errorf("dependency %s failed", dep)
+					e.setStatus(item.name, stage, StageStatus{State: StageSkipped, Error: current.err.Error()})
+					logger.Warn(ctx, "Application component skipped",
+						logger.String("component", item.name),
+						logger.String("stage", stage),
+						logger.String("dependency", dep),
+					)
+					return
+				}
+			}
+
+			start := time.Now()
+			e.setStatus(item.name, stage, StageStatus{State: StageRunning, StartedAt: start})
+			if err := fn(item)(ctx, a); err != nil {
+				current.err = fmt.Errorf("failed %s %s component, error: %w", stage, item.name, err)
+				e.setStatus(item.name, stage, StageStatus{
+					State:     StageFailed,
+					StartedAt: start,
+					Duration:  time.Since(start),
+					Error:     err.Error(),
+				})
+				logger.Error(ctx, "Application component failed",
+					logger.String("component", item.name),
+					logger.String("stage", stage),
+					logger.Duration("duration", time.Since(start)),
+					logger.Err(err),
+				)
+
+				mu.Lock()
+				errs = errors.Join(errs, current.err)
+				mu.Unlock()
+				return
+			}
+
+			e.setStatus(item.name, stage, StageStatus{State: StageDone, StartedAt: start, Duration: time.Since(start)})
+			logger.Info(ctx, "Application component done",
+				logger.String("component", item.name),
+				logger.String("stage", stage),
+				logger.Time("started_at", start),
+				logger.Duration("duration", time.Since(start)),
+			)
+		}()
+	}
+
+	wg.Wait()
+	return errs
 }
 
-func NewComponent(name string, init, run ComponentFunc) Component {
+// NewComponent создает компонент, deps - названия компонентов,
+// которые должны быть инициализированы и запущены до него
+func NewComponent(name string, init, run ComponentFunc, deps ...string) Component {
 	return Component{
 		name:   name,
 		initFn: init,
 		runFn:  run,
+		deps:   deps,
 	}
 }
+
+// withCheck проверка конфига компонента, ошибки всех компонентов собираются до init
+func (c Component) withCheck(check func(*Application) error) Component {
+	c.check = check
+	return c
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+const envComponents = "components."
+
+var ErrComponentsConfig = errors.New("components are misconfigured")
+
+// configurableComponents встроенные компоненты без параметров, которые можно включить только конфигом.
+// Компоненты с параметрами (grpc-серверы, auth, ratelimit, воркеры) включаются опциями в коде
+var configurableComponents = []struct {
+	name   string
+	option func() Option
+}{
+	{ComponentMetrics, WithMetrics},
+	{ComponentTrace, WithTrace},
+	{ComponentPostgres, WithDB},
+	{ComponentRedis, WithRedis},
+	{ComponentKafka, WithKafka},
+	{ComponentS3, WithS3},
+	{ComponentWorkflow, WithWorkflow},
+	{ComponentLocalize, WithLocalize},
+	{ComponentFlags, WithFlags},
+	{ComponentHTTP, WithHTTP},
+	{ComponentAdmin, WithAdmin},
+}
+
+// applyComponentsConfig применяет секцию components: true включает встроенный компонент,
+// false убирает любой зарегистрированный компонент, включая воркеры и расписания.
+// Так один бинарник запускается как api, воркер или только миграции
+func (a *Application) applyComponentsConfig(ctx context.Context) error {
+	for _, c := range configurableComponents {
+		if a.components.has(c.name) || !a.Env.GetBool(envComponents+c.name) {
+			continue
+		}
+		if err := c.option()(a); err != nil {
+			return err
+		}
+		logger.Info(ctx, "Component enabled by config", logger.String("component", c.name))
+	}
+
+	for _, name := range slices.Clone(a.components.order) {
+		// контейнер нужен всем остальным компонентам
+		if name == ComponentDI || a.componentEnabled(name) {
+			continue
+		}
+		a.components.remove(name)
+		a.resetComponent(name)
+		logger.Info(ctx, "Component disabled by config", logger.String("component", name))
+	}
+	return nil
+}
+
+// resetComponent сбрасывает то, что опция выключенного компонента успела создать до конфига:
+// иначе выключенный gRPC сервер получал бы интерсепторы и health, а его адрес проверялся бы на конфликт
+func (a *Application) resetComponent(name string) {
+	switch name {
+	case ComponentGrpcPublicServer:
+		a.PublicGrpcServer = nil
+	case ComponentGrpcPrivateServer:
+		a.PrivateGrpcServer = nil
+	case ComponentGrpcClient:
+		a.GrpcClients = nil
+	}
+}
+
+func (a *Application) componentEnabled(name string) bool {
+	return a.Env.GetBoolOrDefault(envComponents+name, true)
+}
+
+// validateComponents проверяет зависимости и конфиг всех компонентов до init,
+// чтобы сообщить обо всех ошибках сразу, а не по одной на каждый запуск
+func (a *Application) validateComponents() error {
+	var errs []error
+	for _, name := range a.components.order {
+		c := a.components.list[name]
+		for _, dep := range c.deps {
+			if a.components.has(dep) {
+				continue
+			}
+			if !a.componentEnabled(dep) {
+				errs = append(errs, fmt.Errorf("%w: %s required by %s is disabled by %s%s", ErrComponentDependencyMissing, dep, name, envComponents, dep))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%w: %s required by %s", ErrComponentDependencyMissing, dep, name))
+		}
+		if c.check == nil {
+			continue
+		}
+		if err := c.check(a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n%w", ErrComponentsConfig, errors.Join(errs...))
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func names(items []component) []string {
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		res = append(res, item.name)
+	}
+	return res
+}
+
+func TestComponentsSorted(t *testing.T) {
+	c := newComponents()
+	c.add(component(NewComponent("worker", Noop, Noop, ComponentPostgres, ComponentRedis)))
+	c.add(component(NewComponent(ComponentRedis, Noop, Noop, ComponentDI)))
+	c.add(component(NewComponent("http", Noop, Noop)))
+	c.add(component(NewComponent(ComponentPostgres, Noop, Noop, ComponentDI)))
+	c.add(component(NewComponent(ComponentDI, Noop, Noop)))
+
+	items, err := c.sorted()
+	require.NoError(t, err)
+	assert.Equal(t, []string{ComponentDI, ComponentPostgres, ComponentRedis, "worker", "http"}, names(items))
+}
+
+func TestComponentsCycle(t *testing.T) {
+	c := newComponents()
+	c.add(component(NewComponent("a", Noop, Noop, "b")))
+	c.add(component(NewComponent("b", Noop, Noop, "c")))
+	c.add(component(NewComponent("c", Noop, Noop, "a")))
+
+	_, err := c.sorted()
+	assert.ErrorIs(t, err, ErrComponentCycle)
+	assert.ErrorContains(t, err, "a -> b -> c -> a")
+
+	err = c.init(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrComponentCycle)
+}
+
+func TestComponentsMissingDependency(t *testing.T) {
+	c := newComponents()
+	c.add(component(NewComponent("worker", Noop, Noop, ComponentPostgres)))
+
+	_, err := c.sorted()
+	assert.ErrorIs(t, err, ErrComponentDependencyMissing)
+	assert.ErrorContains(t, err, "postgres required by worker")
+}
+
+func TestComponentsParallelInit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// оба компонента ждут друг друга, при последовательном запуске упадут по таймауту
+	var wg sync.WaitGroup
+	wg.Add(2)
+	waitBoth := func(ctx context.Context, _ *Application) error {
+		wg.Done()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c := newComponents()
+	c.add(component(NewComponent("a", waitBoth, Noop)))
+	c.add(component(NewComponent("b", waitBoth, Noop)))
+
+	assert.NoError(t, c.init(ctx, nil))
+}
+
+func TestComponentsDependOnContainer(t *testing.T) {
+	var created, built, initAfterCreate, runAfterBuild atomic.Bool
+	slow := func(flag *atomic.Bool) ComponentFunc {
+		return func(context.Context, *Application) error {
+			time.Sleep(50 * time.Millisecond)
+			flag.Store(true)
+			return nil
+		}
+	}
+
+	c := newComponents()
+	c.add(component(NewComponent(ComponentDI, slow(&created), slow(&built))))
+	c.add(component(NewComponent("reports", func(context.Context, *Application) error {
+		initAfterCreate.Store(created.Load())
+		return nil
+	}, func(context.Context, *Application) error {
+		runAfterBuild.Store(built.Load())
+		return nil
+	})))
+
+	require.NoError(t, c.init(context.Background(), nil))
+	require.NoError(t, c.run(context.Background(), nil))
+	assert.True(t, initAfterCreate.Load())
+	assert.True(t, runAfterBuild.Load())
+	assert.Equal(t, []string{ComponentDI}, c.statuses()[1].Deps)
+}
+
+func TestComponentsCollectErrors(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+	var dependentCalled atomic.Bool
+
+	c := newComponents()
+	c.add(component(NewComponent("a", func(context.Context, *Application) error { return errA }, Noop)))
+	c.add(component(NewComponent("b", func(context.Context, *Application) error { return errB }, Noop)))
+	c.add(component(NewComponent("c", func(context.Context, *Application) error {
+		dependentCalled.Store(true)
+		return nil
+	}, Noop, "a")))
+
+	err := c.init(context.Background(), nil)
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+	assert.False(t, dependentCalled.Load())
+}
+
+func TestComponentsConfig(t *testing.T) {
+	ctx := context.Background()
+	newApp := func(values map[string]any) *Application {
+		env := cfg.New("", "")
+		require.NoError(t, env.LoadEnvMap(values))
+		app := &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+			middlewares: middleware.New(), components: newComponents()}
+		app.components.add(component(containerComponent))
+		return app
+	}
+
+	// worker-режим: http и воркер отчетов выключены, redis включен только конфигом
+	app := newApp(map[string]any{
+		"components": map[string]any{"http": false, "reports": false, "redis": true, "di": false},
+		"redis":      map[string]any{"addrs": []string{"localhost:6379"}},
+	})
+	require.NoError(t, WithHTTP()(app))
+	require.NoError(t, WithComponent("reports", Noop, Noop)(app))
+	require.NoError(t, WithComponent("billing", Noop, Noop, ComponentRedis)(app))
+	require.NoError(t, app.applyComponentsConfig(ctx))
+	require.NoError(t, app.validateComponents())
+	assert.Equal(t, []string{ComponentDI, "billing", ComponentRedis}, app.components.order)
+	assert.Len(t, app.components.statuses(), 3)
+
+	// включенные, но не настроенные компоненты падают одной ошибкой
+	app = newApp(map[string]any{
+		"components": map[string]any{"redis": false, "trace": true, "workflow": true},
+	})
+	require.NoError(t, WithIdempotency()(app))
+	require.NoError(t, WithRedis()(app))
+	require.NoError(t, app.applyComponentsConfig(ctx))
+	err := app.validateComponents()
+	assert.ErrorIs(t, err, ErrComponentsConfig)
+	assert.ErrorIs(t, err, ErrComponentDependencyMissing)
+	assert.ErrorIs(t, err, ErrWorkflowHostNotConfigured)
+	assert.ErrorContains(t, err, "redis required by idempotency is disabled by components.redis")
+	assert.ErrorContains(t, err, "trace: trace.endpoint is required")
+}
+
+func TestComponentsConfigResetsDisabled(t *testing.T) {
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{
+		"components": map[string]any{ComponentGrpcPublicServer: false},
+	}))
+	app := &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+		middlewares: middleware.New(), components: newComponents()}
+	require.NoError(t, WithAuth(nil)(app))
+	require.NoError(t, WithPublicGrpcServer(registerTestPayments, &testPayments{})(app))
+	require.NoError(t, WithPrivateGrpcServer(registerTestPayments, &testPayments{})(app))
+	require.NoError(t, app.applyComponentsConfig(context.Background()))
+
+	// выключенный сервер не получает интерсепторы и не участвует в health и проверке адресов
+	assert.Nil(t, app.PublicGrpcServer)
+	assert.Equal(t, []*grpcserver.Manager{app.PrivateGrpcServer}, app.grpcServers())
+}
@@ -3,7 +3,6 @@ package application
 import (
 	"strconv"
 	"strings"
-	"time"
 
 	"git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
 	cfg "git.vepay.dev/knoknok/backend-platform/pkg/config"
@@ -14,10 +13,10 @@ const (
 	envKafkaBrokers = "kafka.brokers"
 	envKafkaGroup   = "kafka.group"
 	envAppName      = cfg.EnvAppName
-	envHTTPPort     = "app.port"
 	envMetricsAddr  = "metrics.addr"
 	envMetricsPort  = "metrics.port"
-	envHTTPHost     = "app.host"
+	envAdminAddr    = "admin.addr"
+	envAdminPort    = "admin.port"
 
 	envRedisAddrs        = "redis.addrs"
 	envRedisDb           = "redis.db"
@@ -30,6 +29,7 @@ const (
 
 	defaultKafkaBroker = "kafka:9092"
 	defaultMetricsPort = 9091
+	defaultAdminPort   = 9092
 )
 
 type appConfig struct {
@@ -61,30 +61,6 @@ func (a *appConfig) GetKafkaConfig() kafkaConfig {
 	}
 }
 
-// http
-
-type httpServerConfig struct {
-	Host         string
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
-}
-
-func (h *httpServerConfig) GetAddr() string {
-	return strings.Join([]string{h.Host, h.Port}, ":")
-}
-
-func (a *appConfig) GetHTTPServerConfig() httpServerConfig {
-	return httpServerConfig{
-		Host:         getStringOrDefault(a.GetString(envHTTPHost), ""),
-		Port:         getStringOrDefault(a.GetString(envHTTPPort), "8080"),
-		ReadTimeout:  60 * time.Second,
-		WriteTimeout: 60 * time.Second,
-		IdleTimeout:  120 * time.Second,
-	}
-}
-
 // redis
 
 func (a *appConfig) GetRedisConfig() redis.RedisConfig {
@@ -127,3 +103,22 @@ func (a *appConfig) GetMetricsAddr() string {
 
 	return ":" + port
 }
+
+// admin
+
+func (a *appConfig) GetAdminAddr() string {
+	if addr := a.GetString(envAdminAddr); addr != "" {
+		return addr
+	}
+
+	port := a.GetString(envAdminPort)
+	if port == "" {
+		port = strconv.Itoa(defaultAdminPort)
+	}
+
+	if strings.HasPrefix(port, ":") {
+		return port
+	}
+
+	return ":" + port
+}
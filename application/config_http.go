@@ -0,0 +1,144 @@
+package application
+
+import (
+	"crypto/tls"
+	"strings"
+	"time"
+)
+
+const (
+	envHTTPPort      = "app.port"
+	envHTTPHost      = "app.host"
+	envHTTPServer    = "app.http"
+	envHTTPListeners = "app.listeners"
+
+	envHTTPRequestID = "app.middlewares.request_id"
+	envHTTPRecovery  = "app.middlewares.recovery"
+	envHTTPMetrics   = "app.middlewares.metrics"
+	envHTTPTrace     = "app.middlewares.trace"
+	envHTTPMaxRoutes = "app.middlewares.metrics_max_routes"
+
+	defaultHTTPPort              = "8080"
+	defaultHTTPReadTimeout       = 60 * time.Second
+	defaultHTTPReadHeaderTimeout = 10 * time.Second
+	defaultHTTPWriteTimeout      = 60 * time.Second
+	defaultHTTPIdleTimeout       = 120 * time.Second
+	defaultHTTPMaxHeaderBytes    = 1 << 20
+	defaultHTTPMaxRoutes         = 500
+)
+
+// http
+
+type httpServerConfig struct {
+	Host              string
+	Port              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	HTTP2             bool // h2 поверх TLS
+	H2C               bool // HTTP/2 без TLS, например за балансировщиком с h2c
+	TLS               httpTLSConfig
+}
+
+type httpTLSConfig struct {
+	Enabled        bool
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	VaultPath      string // секрет KV с полями certificate, private_key, ca вместо файлов
+	ClientAuth     tls.ClientAuthType
+	ReloadInterval time.Duration
+}
+
+func (h *httpServerConfig) GetAddr() string {
+	return strings.Join([]string{h.Host, h.Port}, ":")
+}
+
+// GetHTTPServerConfig основной http-сервер: app.host, app.port и настройки app.http
+func (a *appConfig) GetHTTPServerConfig() httpServerConfig {
+	cfg := a.readHTTPServerConfig(envHTTPServer, httpServerConfig{
+		ReadTimeout:       defaultHTTPReadTimeout,
+		ReadHeaderTimeout: defaultHTTPReadHeaderTimeout,
+		WriteTimeout:      defaultHTTPWriteTimeout,
+		IdleTimeout:       defaultHTTPIdleTimeout,
+		MaxHeaderBytes:    defaultHTTPMaxHeaderBytes,
+		HTTP2:             true,
+	})
+	cfg.Host = getStringOrDefault(a.GetString(envHTTPHost), "")
+	cfg.Port = getStringOrDefault(a.GetString(envHTTPPort), defaultHTTPPort)
+	return cfg
+}
+
+// GetHTTPListenerConfig дополнительный http-сервер app.listeners.<name>,
+// не заданные таймауты и лимиты берутся из настроек основного сервера, TLS не наследуется
+func (a *appConfig) GetHTTPListenerConfig(name string) httpServerConfig {
+	def := a.GetHTTPServerConfig()
+	def.TLS = httpTLSConfig{}
+
+	prefix := envHTTPListeners + "." + name
+	cfg := a.readHTTPServerConfig(prefix, def)
+	cfg.Host = getStringOrDefault(a.GetString(prefix+".host"), "")
+	cfg.Port = a.GetString(prefix + ".port")
+	return cfg
+}
+
+func (a *appConfig) readHTTPServerConfig(prefix string, def httpServerConfig) httpServerConfig {
+	cfg := httpServerConfig{
+		ReadTimeout:       getDurationOrDefault(a.GetDuration(prefix+".read_timeout"), def.ReadTimeout),
+		ReadHeaderTimeout: getDurationOrDefault(a.GetDuration(prefix+".read_header_timeout"), def.ReadHeaderTimeout),
+		WriteTimeout:      getDurationOrDefault(a.GetDuration(prefix+".write_timeout"), def.WriteTimeout),
+		IdleTimeout:       getDurationOrDefault(a.GetDuration(prefix+".idle_timeout"), def.IdleTimeout),
+		MaxHeaderBytes:    a.GetIntOrDefault(prefix+".max_header_bytes", def.MaxHeaderBytes),
+		HTTP2:             a.GetBoolOrDefault(prefix+".http2", def.HTTP2),
+		H2C:               a.GetBoolOrDefault(prefix+".h2c", def.H2C),
+		TLS: httpTLSConfig{
+			Enabled:        a.GetBoolOrDefault(prefix+".tls.enabled", def.TLS.Enabled),
+			CertFile:       getStringOrDefault(a.GetString(prefix+".tls.cert_file"), def.TLS.CertFile),
+			KeyFile:        getStringOrDefault(a.GetString(prefix+".tls.key_file"), def.TLS.KeyFile),
+			ClientCAFile:   getStringOrDefault(a.GetString(prefix+".tls.client_ca_file"), def.TLS.ClientCAFile),
+			VaultPath:      getStringOrDefault(a.GetString(prefix+".tls.vault_path"), def.TLS.VaultPath),
+			ClientAuth:     parseClientAuth(a.GetString(prefix+".tls.client_auth"), def.TLS.ClientAuth),
+			ReloadInterval: getDurationOrDefault(a.GetDuration(prefix+".tls.reload_interval"), def.TLS.ReloadInterval),
+		},
+	}
+	// CA клиентов без client_auth - это mTLS, а не молча выключенная проверка сертификатов
+	if cfg.TLS.ClientCAFile != "" && cfg.TLS.ClientAuth == tls.NoClientCert && a.GetString(prefix+".tls.client_auth") == "" {
+		cfg.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg
+}
+
+// parseClientAuth режим mTLS: none, request (проверяется если передан), require
+func parseClientAuth(value string, def tls.ClientAuthType) tls.ClientAuthType {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "none":
+		return tls.NoClientCert
+	case "request":
+		return tls.VerifyClientCertIfGiven
+	case "require":
+		return tls.RequireAndVerifyClientCert
+	default:
+		return def
+	}
+}
+
+// httpMiddlewaresConfig мидлвари http-сервера по умолчанию, все включены
+type httpMiddlewaresConfig struct {
+	Trace     bool // серверные спаны, только при WithTrace
+	RequestID bool // X-Request-Id и correlationId в контексте
+	Recovery  bool // перехват паники в обработчике, ответ 500
+	Metrics   bool // http_requests_total и http_request_duration_seconds
+	MaxRoutes int  // лимит различных значений метки path, 0 - без ограничения
+}
+
+func (a *appConfig) GetHTTPMiddlewaresConfig() httpMiddlewaresConfig {
+	return httpMiddlewaresConfig{
+		Trace:     a.GetBoolOrDefault(envHTTPTrace, true),
+		RequestID: a.GetBoolOrDefault(envHTTPRequestID, true),
+		Recovery:  a.GetBoolOrDefault(envHTTPRecovery, true),
+		Metrics:   a.GetBoolOrDefault(envHTTPMetrics, true),
+		MaxRoutes: a.GetIntOrDefault(envHTTPMaxRoutes, defaultHTTPMaxRoutes),
+	}
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+)
+
+const (
+	envShutdownTimeout          = "app.shutdown.timeout"
+	envShutdownDrainDelay       = "app.shutdown.drain_delay"
+	envShutdownServersTimeout   = "app.shutdown.servers_timeout"
+	envShutdownDefaultTimeout   = "app.shutdown.default_timeout"
+	envShutdownStoragesTimeout  = "app.shutdown.storages_timeout"
+	envShutdownTelemetryTimeout = "app.shutdown.telemetry_timeout"
+)
+
+type shutdownConfig struct {
+	Timeout       time.Duration // общее время на выполнение closers
+	DrainDelay    time.Duration // пауза после снятия readiness, чтобы балансировщик исключил под
+	PhaseTimeouts map[closers.Phase]time.Duration
+}
+
+func (a *appConfig) GetShutdownConfig() shutdownConfig {
+	return shutdownConfig{
+		Timeout:    getDurationOrDefault(a.GetDuration(envShutdownTimeout), defaultWaitCloserTime),
+		DrainDelay: a.GetDuration(envShutdownDrainDelay),
+		PhaseTimeouts: map[closers.Phase]time.Duration{
+			closers.PhaseServers:   a.GetDuration(envShutdownServersTimeout),
+			closers.PhaseDefault:   a.GetDuration(envShutdownDefaultTimeout),
+			closers.PhaseStorages:  a.GetDuration(envShutdownStoragesTimeout),
+			closers.PhaseTelemetry: a.GetDuration(envShutdownTelemetryTimeout),
+		},
+	}
+}
@@ -3,7 +3,7 @@ package application
 import (
 	"context"
 
-	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 )
 
 // initConfig запускает слушатель изменения конфига
@@ -11,14 +11,5 @@ func (a *Application) initConfig(ctx context.Context) {
 	// подписка на изменения
 	go a.Env.Watch(ctx)
 
-	a.Closer.Add(func() error {
-		err := a.Env.Close(ctx)
-		if err == nil {
-			logger.Info(ctx, "Config watchers closed successfully")
-			return nil
-		}
-
-		logger.Error(ctx, "Config watchers closed with error", logger.Err(err))
-		return err
-	})
+	a.Closer.Add("config", a.Env.Close, closers.WithPhase(closers.PhaseTelemetry))
 }
@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	containerComponent = NewComponent("di", initContainer, runContainer)
+	containerComponent = NewComponent(ComponentDI, initContainer, runContainer)
 )
 
 // initContainerClient создает глобальный контейнер
@@ -4,19 +4,20 @@ import (
 	"context"
 	"fmt"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/db"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 )
 
 var (
-	dbComponent = NewComponent("postgres", initPostgresClient, runPostgresClient)
+	dbComponent = NewComponent(ComponentPostgres, initPostgresClient, runPostgresClient, ComponentDI)
 )
 
 // WithDB добавляет компонент базы данных в сервис (Postgres)
 func WithDB() Option {
 	return func(app *Application) error {
-		app.components.addFirst(component(dbComponent))
+		app.components.add(component(dbComponent))
 		return nil
 	}
 }
@@ -54,7 +55,7 @@ func runPostgresClient(ctx context.Context, app *Application) error {
 	}
 	logger.Info(ctx, "Migrations applied successfully", logger.Int("applied", applied))
 
-	app.Closer.Add(manager.Close)
+	app.Closer.Add(ComponentPostgres, closers.Wrap(manager.Close), closers.WithPhase(closers.PhaseStorages))
 	app.Health.Add("postgres", func(ctx context.Context) error {
 		_, err := manager.HealthStatus()
 		return err
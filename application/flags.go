@@ -0,0 +1,61 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	cfg "git.vepay.dev/knoknok/backend-platform/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/flags"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+const (
+	envFlagsPrefix = "flags.prefix"
+	envFlagsFile   = "flags.file"
+
+	defaultFlagsPrefix = "flags/"
+	defaultFlagsFile   = "flags.yaml"
+)
+
+var (
+	flagsComponent = NewComponent(ComponentFlags, initFlags, Noop, ComponentDI)
+)
+
+// WithFlags фиче-флаги из consul KV (flags.prefix, по умолчанию flags/<app.name>) с обновлением без перезапуска.
+// Без consul флаги читаются из локального файла flags.file (по умолчанию flags.yaml).
+// Доступны через app.Flags, di и flags.IsEnabled(ctx, name)
+func WithFlags() Option {
+	return func(app *Application) error {
+		app.components.add(component(flagsComponent))
+		return nil
+	}
+}
+
+func initFlags(ctx context.Context, app *Application) error {
+	store := flags.NewStore()
+
+	if client := cfg.GetConsulClient(); client != nil {
+		prefix := app.Env.GetStringOrDefault(envFlagsPrefix, defaultFlagsPrefix+app.Env.GetString(cfg.EnvAppName))
+		logger.Info(ctx, "Feature flags from consul", logger.String("prefix", prefix))
+		if err := flags.LoadConsul(ctx, store, client, prefix); err != nil {
+			return err
+		}
+	} else {
+		path := app.Env.GetStringOrDefault(envFlagsFile, defaultFlagsFile)
+		err := flags.LoadFile(store, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			logger.Warn(ctx, "Feature flags file not found, all flags are disabled", logger.String("file", path))
+		case err != nil:
+			return err
+		default:
+			logger.Info(ctx, "Feature flags from file", logger.String("file", path))
+		}
+	}
+
+	app.Flags = store
+	di.Register[flags.Flags](ctx, app.Flags)
+	return nil
+}
@@ -3,13 +3,17 @@ package application
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
 	grpc1 "git.vepay.dev/knoknok/backend-platform/pkg/grpc"
 	"git.vepay.dev/knoknok/backend-platform/pkg/grpc/client"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 )
 
 var (
-	grpcClientComponent = NewComponent("grpc-client", initGrpcClient, Noop)
+	grpcClientComponent = NewComponent(ComponentGrpcClient, initGrpcClient, Noop, ComponentDI)
 )
 
 // WithGrpcClient
@@ -27,6 +31,19 @@ func WithGrpcClient[TClient any](serviceName string, constructor any) Option {
 	}
 }
 
+// addGrpcClientProbes активные проверки клиентов с grpc.client.<name>.health.mode, опрашиваются в фоне
+func (a *Application) addGrpcClientProbes() {
+	for service, cfg := range a.GrpcClients.Probes() {
+		opts := []health.CheckOption{health.Interval(cfg.Interval), health.Timeout(cfg.Timeout)}
+		if !cfg.Critical {
+			opts = append(opts, health.Optional())
+		}
+		a.Health.Add("grpc-client:"+strings.TrimPrefix(service, grpcClientPrefix), func(ctx context.Context) error {
+			return a.GrpcClients.Probe(ctx, service)
+		}, opts...)
+	}
+}
+
 func initGrpcClient(ctx context.Context, app *Application) error {
 	if app.GrpcClients == nil {
 		return fmt.Errorf("gRPC client manager not created")
@@ -43,6 +60,13 @@ func initGrpcClient(ctx context.Context, app *Application) error {
 			MaxSendMsgSize:   cfg.MaxSendMsgSize,
 			KeepAliveTime:    cfg.KeepAliveTime,
 			KeepAliveTimeout: cfg.KeepAliveTimeout,
+			Health: client.HealthCheckConfig{
+				Mode:     cfg.Health.Mode,
+				Service:  cfg.Health.Service,
+				Interval: cfg.Health.Interval,
+				Timeout:  cfg.Health.Timeout,
+				Critical: cfg.Health.Critical,
+			},
 		}
 	}
 
@@ -52,6 +76,7 @@ func initGrpcClient(ctx context.Context, app *Application) error {
 	}
 
 	app.Health.Add("grpc-clients", app.GrpcClients.HealthCheck)
-	app.Closer.Add(app.GrpcClients.Close)
+	app.addGrpcClientProbes()
+	app.Closer.Add(ComponentGrpcClient, closers.Wrap(app.GrpcClients.Close), closers.WithPhase(closers.PhaseStorages))
 	return nil
 }
@@ -32,6 +32,11 @@ const (
 	cfgMaxSendMsgSize   = ".max_send_msg_size"
 	cfgKeepAliveTime    = ".keepalive_time"
 	cfgKeepAliveTimeout = ".keepalive_timeout"
+	cfgHealthMode       = ".health.mode"
+	cfgHealthService    = ".health.service"
+	cfgHealthInterval   = ".health.interval"
+	cfgHealthTimeout    = ".health.timeout"
+	cfgHealthCritical   = ".health.critical"
 
 	// defaults
 	defaultGrpcPrivatePort       = "50051"
@@ -42,6 +47,10 @@ const (
 	defaultGrpcKeepAliveTime     = 30 * time.Second
 	defaultGrpcKeepAliveTimeout  = 10 * time.Second
 	defaultGrpcClientTimeout     = 30 * time.Second
+
+	// активная проверка клиента
+	defaultGrpcClientHealthInterval = 10 * time.Second
+	defaultGrpcClientHealthTimeout  = 2 * time.Second
 )
 
 type grpcServerConfig struct {
@@ -92,6 +101,16 @@ type grpcClientConfig struct {
 	MaxSendMsgSize   int
 	KeepAliveTime    time.Duration
 	KeepAliveTimeout time.Duration
+	Health           grpcClientHealthConfig
+}
+
+// grpcClientHealthConfig активная проверка вышестоящего сервиса, mode: state или rpc
+type grpcClientHealthConfig struct {
+	Mode     string
+	Service  string
+	Interval time.Duration
+	Timeout  time.Duration
+	Critical bool
 }
 
 func (a *appConfig) GetGrpcClientConfig(serviceName string) grpcClientConfig {
@@ -107,6 +126,13 @@ func (a *appConfig) GetGrpcClientConfig(serviceName string) grpcClientConfig {
 		MaxSendMsgSize:   getIntOrDefault(a.GetInt(base+cfgMaxSendMsgSize), defaultGrpcMaxSendMsgSize),
 		KeepAliveTime:    getDurationOrDefault(a.GetDuration(base+cfgKeepAliveTime), defaultGrpcKeepAliveTime),
 		KeepAliveTimeout: getDurationOrDefault(a.GetDuration(base+cfgKeepAliveTimeout), defaultGrpcKeepAliveTimeout),
+		Health: grpcClientHealthConfig{
+			Mode:     a.GetString(base + cfgHealthMode),
+			Service:  a.GetString(base + cfgHealthService),
+			Interval: getDurationOrDefault(a.GetDuration(base+cfgHealthInterval), defaultGrpcClientHealthInterval),
+			Timeout:  getDurationOrDefault(a.GetDuration(base+cfgHealthTimeout), defaultGrpcClientHealthTimeout),
+			Critical: a.GetBool(base + cfgHealthCritical),
+		},
 	}
 }
 
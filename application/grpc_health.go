@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
+	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+const (
+	envGrpcHealthInterval     = "app.healthz.grpc_interval"
+	defaultGrpcHealthInterval = 5 * time.Second
+)
+
+// WithGrpcServiceHealth статус сервиса в grpc health зависит не только от готовности приложения,
+// но и от указанных проверок app.Health, в том числе необязательных.
+// service - полное имя сервиса, например payments.v1.PaymentService
+func WithGrpcServiceHealth(service string, checks ...string) Option {
+	return func(app *Application) error {
+		if app.grpcServiceChecks == nil {
+			app.grpcServiceChecks = make(map[string][]string)
+		}
+		app.grpcServiceChecks[service] = append(app.grpcServiceChecks[service], checks...)
+		return nil
+	}
+}
+
+func (a *Application) grpcServers() []*grpcserver.Manager {
+	var servers []*grpcserver.Manager
+	for _, server := range []*grpcserver.Manager{a.PublicGrpcServer, a.PrivateGrpcServer} {
+		if server != nil {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// syncGrpcHealth переносит результат /healthz/ready в grpc health каждые app.healthz.grpc_interval,
+// с началом остановки все сервисы сразу становятся NOT_SERVING
+func (a *Application) syncGrpcHealth(ctx context.Context) {
+	servers := a.grpcServers()
+	if len(servers) == 0 {
+		return
+	}
+	for service := range a.grpcServiceChecks {
+		if !slices.ContainsFunc(servers, func(s *grpcserver.Manager) bool { return slices.Contains(s.Services(), service) }) {
+			logger.Warn(ctx, "gRPC health service not registered", logger.String("service", service))
+		}
+	}
+
+	ticker := time.NewTicker(getDurationOrDefault(a.Env.GetDuration(envGrpcHealthInterval), defaultGrpcHealthInterval))
+	defer ticker.Stop()
+	for {
+		a.updateGrpcHealth(ctx, servers)
+		select {
+		case <-a.closing:
+			for _, server := range servers {
+				server.SetAllServing(false)
+			}
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Application) updateGrpcHealth(ctx context.Context, servers []*grpcserver.Manager) {
+	ctx, cancel := context.WithTimeout(ctx, defaultReadinessTimeout)
+	defer cancel()
+
+	response := checkReadiness(ctx, a)
+	ready := response.Code == http.StatusOK
+	down := make(map[string]bool)
+	for _, res := range response.Checks {
+		down[res.Name] = res.Status == health.StatusDown
+	}
+
+	for _, server := range servers {
+		server.SetServingStatus("", ready)
+		for _, service := range server.Services() {
+			serving := ready && !slices.ContainsFunc(a.grpcServiceChecks[service], func(check string) bool { return down[check] })
+			server.SetServingStatus(service, serving)
+		}
+	}
+}
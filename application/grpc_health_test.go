@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
+	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGrpcServiceHealth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	app := &Application{
+		Health:    health.New(),
+		Readiness: health.New(),
+		started:   make(chan struct{}),
+		closing:   make(chan struct{}),
+	}
+	close(app.started)
+
+	searchErr := errors.New("search unavailable")
+	app.Health.Add("search", func(context.Context) error { return searchErr }, health.Optional())
+	require.NoError(t, WithGrpcServiceHealth("test.v1.Search", "search")(app))
+
+	app.PublicGrpcServer = grpcserver.New(grpcserver.Config{Addr: addr, MaxRecvMsgSize: 4 << 20, MaxSendMsgSize: 4 << 20, ConnectionTimeout: time.Second})
+	for _, name := range []string{"test.v1.Search", "test.v1.Payments"} {
+		app.PublicGrpcServer.AddService(func(s grpc.ServiceRegistrar) {
+			s.RegisterService(&grpc.ServiceDesc{ServiceName: name, HandlerType: (*any)(nil)}, struct{}{})
+		})
+	}
+	require.NoError(t, app.PublicGrpcServer.Initialize(ctx))
+	require.NoError(t, app.PublicGrpcServer.Start(ctx))
+	defer app.PublicGrpcServer.Stop()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	hc := grpc_health_v1.NewHealthClient(conn)
+	serving := func(service string) bool {
+		resp, err := hc.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	// приложение degraded: сервис, зависящий от упавшей проверки, снят
+	app.updateGrpcHealth(ctx, app.grpcServers())
+	assert.True(t, serving(""))
+	assert.True(t, serving("test.v1.Payments"))
+	assert.False(t, serving("test.v1.Search"))
+
+	searchErr = nil
+	app.updateGrpcHealth(ctx, app.grpcServers())
+	assert.True(t, serving("test.v1.Search"))
+
+	// остановка снимает все сервисы
+	close(app.closing)
+	app.updateGrpcHealth(ctx, app.grpcServers())
+	assert.False(t, serving(""))
+	assert.False(t, serving("test.v1.Payments"))
+}
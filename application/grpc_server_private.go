@@ -3,13 +3,14 @@ package application
 import (
 	"context"
 	"fmt"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"google.golang.org/grpc"
 )
 
 var (
-	grpcPrivateServerComponent = NewComponent("grpc-private-server", initPrivateGrpcServer, runPrivateGrpcServer)
+	grpcPrivateServerComponent = NewComponent(ComponentGrpcPrivateServer, initPrivateGrpcServer, runPrivateGrpcServer)
 )
 
 // helper
@@ -69,7 +70,9 @@ func initPrivateGrpcServer(ctx context.Context, app *Application) error {
 	if err := app.PrivateGrpcServer.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize private gRPC server: %w", err)
 	}
-	app.Closer.Add(app.PrivateGrpcServer.Stop)
+	// NOT_SERVING до готовности приложения, дальше статус синхронизирует syncGrpcHealth
+	app.PrivateGrpcServer.SetAllServing(false)
+	app.Closer.Add(ComponentGrpcPrivateServer, app.PrivateGrpcServer.Shutdown, closers.WithPhase(closers.PhaseServers))
 	return nil
 }
 
@@ -77,6 +80,9 @@ func runPrivateGrpcServer(ctx context.Context, app *Application) error {
 	if app.PrivateGrpcServer == nil {
 		return fmt.Errorf("private gRPC server not initialized")
 	}
+	app.PrivateGrpcServer.OnError(func(err error) {
+		app.Fail(ComponentGrpcPrivateServer, err)
+	})
 	if err := app.PrivateGrpcServer.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start private gRPC server: %w", err)
 	}
@@ -3,13 +3,14 @@ package application
 import (
 	"context"
 	"fmt"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	grpcserver "git.vepay.dev/knoknok/backend-platform/pkg/grpc/server"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"google.golang.org/grpc"
 )
 
 var (
-	grpcPublicServerComponent = NewComponent("grpc-public-server", initPublicGrpcServer, runPublicGrpcServer)
+	grpcPublicServerComponent = NewComponent(ComponentGrpcPublicServer, initPublicGrpcServer, runPublicGrpcServer)
 )
 
 func (a *Application) addPublicGrpcServer() {
@@ -67,7 +68,9 @@ func initPublicGrpcServer(ctx context.Context, app *Application) error {
 	if err := app.PublicGrpcServer.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize public gRPC server: %w", err)
 	}
-	app.Closer.Add(app.PublicGrpcServer.Stop)
+	// NOT_SERVING до готовности приложения, дальше статус синхронизирует syncGrpcHealth
+	app.PublicGrpcServer.SetAllServing(false)
+	app.Closer.Add(ComponentGrpcPublicServer, app.PublicGrpcServer.Shutdown, closers.WithPhase(closers.PhaseServers))
 	return nil
 }
 
@@ -75,6 +78,9 @@ func runPublicGrpcServer(ctx context.Context, app *Application) error {
 	if app.PublicGrpcServer == nil {
 		return fmt.Errorf("public gRPC server not initialized")
 	}
+	app.PublicGrpcServer.OnError(func(err error) {
+		app.Fail(ComponentGrpcPublicServer, err)
+	})
 	if err := app.PublicGrpcServer.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start public gRPC server: %w", err)
 	}
@@ -2,16 +2,52 @@ package application
 
 import (
 	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
 )
 
 const (
+	envHealthzVerbose = "app.healthz.verbose"
+	envHealthz        = "app.healthz."
+	envHealthzChecks  = "app.healthz.checks."
+
 	defaultLivenessTimeout  = time.Second * 30
 	defaultReadinessTimeout = time.Second * 5
 )
 
-// addProbes add liveness and readiness probes
+// addProbes add liveness, readiness and startup probes
 func (a *Application) addProbes() {
 	a.middlewares.Add(a.livenessMiddleware)
+	a.middlewares.Add(a.startupMiddleware)
 	a.middlewares.Add(a.readinessMiddleware)
 	a.components.add(component(httpServer))
 }
+
+// newHealth health check приложения: app.healthz.* задает настройки всех проверок,
+// app.healthz.checks.<name>.* - настройки одной проверки поверх опций из кода
+func newHealth(cfg appConfig) health.Health {
+	return health.New(
+		health.WithDefaults(cfg.healthCheckOptions(envHealthz)...),
+		health.WithOverrides(func(name string) []health.CheckOption {
+			return cfg.healthCheckOptions(envHealthzChecks + name + ".")
+		}),
+	)
+}
+
+// healthCheckOptions только заданные в конфиге interval, timeout, failure_threshold, success_threshold
+func (a *appConfig) healthCheckOptions(prefix string) []health.CheckOption {
+	var opts []health.CheckOption
+	if v := a.GetDuration(prefix + "interval"); v > 0 {
+		opts = append(opts, func(c *health.Check) { c.Interval = v })
+	}
+	if v := a.GetDuration(prefix + "timeout"); v > 0 {
+		opts = append(opts, func(c *health.Check) { c.Timeout = v })
+	}
+	if v := a.GetInt(prefix + "failure_threshold"); v > 0 {
+		opts = append(opts, func(c *health.Check) { c.FailureThreshold = v })
+	}
+	if v := a.GetInt(prefix + "success_threshold"); v > 0 {
+		opts = append(opts, func(c *health.Check) { c.SuccessThreshold = v })
+	}
+	return opts
+}
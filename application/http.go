@@ -3,15 +3,24 @@ package application
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"sync"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/certs"
+	cfg "git.vepay.dev/knoknok/backend-platform/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/pkg/httproute"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 )
 
 var (
-	ErrHTTPServerNotFound = errors.New("http router not defined, use app.RegisterRouter")
-	httpServer            = NewComponent("http", Noop, runHTTP)
+	ErrHTTPServerNotFound    = errors.New("http router not defined, use app.RegisterRouter")
+	ErrHTTPListenerPort      = errors.New("http listener port not configured")
+	ErrHTTPVaultNotAvailable = errors.New("vault is disabled, tls.vault_path can not be used")
+	httpServer               = NewComponent(ComponentHTTP, Noop, runHTTP)
 )
 
 // WithHTTP add httpServer component, started on HTTP_PORT.
@@ -25,8 +34,59 @@ func WithHTTP() Option {
 }
 
 // RegisterRouter add custom router: echo, gin, etc.
+// Для http.ServeMux шаблоны маршрутов попадают в метрики автоматически, для echo и chi см. pkg/httproute
 func (a *Application) RegisterRouter(router http.Handler) {
-	a.router = router
+	a.router = withRoutePattern(router)
+}
+
+// withRoutePattern сообщает шаблоны маршрутов http.ServeMux
+func withRoutePattern(router http.Handler) http.Handler {
+	if mux, ok := router.(*http.ServeMux); ok {
+		return httproute.ServeMux(mux)
+	}
+	return router
+}
+
+// Handler возвращает роутер приложения с мидлварями в том виде, в котором его обслуживает http-сервер
+func (a *Application) Handler() http.Handler {
+	return a.middlewares.Chain()(a.router.ServeHTTP)
+}
+
+// HTTPListener дополнительный http-сервер со своим роутером и цепочкой мидлварей
+type HTTPListener struct {
+	name        string
+	router      http.Handler
+	middlewares middleware.Middlewares
+}
+
+// Use добавляет мидлвари листенера, выполняются после мидлварей по умолчанию (request id, метрики, recovery)
+func (l *HTTPListener) Use(mws ...middleware.Middleware) *HTTPListener {
+	for _, mw := range mws {
+		l.middlewares.Add(mw)
+	}
+	return l
+}
+
+// Handler роутер листенера с мидлварями
+func (l *HTTPListener) Handler() http.Handler {
+	return l.middlewares.Chain()(l.router.ServeHTTP)
+}
+
+// RegisterListener регистрирует роутер на отдельном порту, например для внутренних колбэков.
+// Адрес, таймауты и TLS берутся из app.listeners.<name>, пробы k8s обслуживает только основной сервер.
+// Листенеры запускаются вместе с компонентом http, поэтому регистрируются до Run
+func (a *Application) RegisterListener(name string, router http.Handler) *HTTPListener {
+	for _, l := range a.listeners {
+		if l.name == name {
+			l.router = withRoutePattern(router)
+			return l
+		}
+	}
+
+	l := &HTTPListener{name: name, router: withRoutePattern(router), middlewares: middleware.New()}
+	a.addDefaultMiddlewares(l.middlewares)
+	a.listeners = append(a.listeners, l)
+	return l
 }
 
 func runHTTP(ctx context.Context, a *Application) error {
@@ -34,41 +94,103 @@ func runHTTP(ctx context.Context, a *Application) error {
 		return ErrHTTPServerNotFound
 	}
 
-	a.httpServer = a.createServer()
-	a.httpServer.Handler = a.middlewares.Chain()(a.router.ServeHTTP)
+	server, err := a.startHTTPServer(ctx, ComponentHTTP, a.config.GetHTTPServerConfig(), a.Handler())
+	if err != nil {
+		return err
+	}
+	a.httpServer = server
 
-	a.Closer.Add(func() error {
-		return a.httpServer.Shutdown(ctx)
-	})
+	for _, l := range a.listeners {
+		cfg := a.config.GetHTTPListenerConfig(l.name)
+		if cfg.Port == "" {
+			return fmt.Errorf("%w: %s", ErrHTTPListenerPort, l.name)
+		}
+		if _, err := a.startHTTPServer(ctx, ComponentHTTP+":"+l.name, cfg, l.Handler()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startHTTPServer создает сервер, регистрирует его остановку и запускает в отдельной горутине
+func (a *Application) startHTTPServer(ctx context.Context, name string, cfg httpServerConfig, handler http.Handler) (*http.Server, error) {
+	ctx = logger.With(ctx, logger.String("server", name))
+	server := a.createServer(cfg)
+	server.Handler = handler
+
+	// сертификаты обновляются до остановки сервера
+	reloadCtx, stopReload := context.WithCancel(ctx)
+	if cfg.TLS.Enabled {
+		reloader, err := a.certificateReloader(reloadCtx, name, cfg.TLS)
+		if err != nil {
+			stopReload()
+			return nil, fmt.Errorf("%s tls: %w", name, err)
+		}
+		server.TLSConfig = reloader.TLSConfig(cfg.TLS.ClientAuth)
+		go reloader.Run(reloadCtx)
+	}
+
+	a.Closer.Add(name, func(ctx context.Context) error {
+		defer stopReload()
+		return server.Shutdown(ctx)
+	}, closers.WithPhase(closers.PhaseServers))
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
 		wg.Done()
-		logger.Info(ctx, "Running HTTP server", logger.String("addr", a.httpServer.Addr))
+		logger.Info(ctx, "Running HTTP server", logger.String("addr", server.Addr), logger.Bool("tls", cfg.TLS.Enabled))
 		if a.testMode {
 			return
 		}
-		err := a.httpServer.ListenAndServe()
+		err := serve(server)
 		// если получили ошибку которая появилась не из-за шатдауна то надо убивать приложение
 		if err != nil && err != http.ErrServerClosed {
-			logger.Error(ctx, "Application component error",
-				logger.String("component", "http"),
-				logger.Err(err),
-			)
-			a.stop()
+			a.Fail(name, err)
 		}
 	}()
 	wg.Wait()
-	return nil
+	return server, nil
 }
 
-func (a *Application) createServer() *http.Server {
-	cfg := a.config.GetHTTPServerConfig()
+func serve(server *http.Server) error {
+	if server.TLSConfig == nil {
+		return server.ListenAndServe()
+	}
+
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	// сертификат отдается через TLSConfig.GetCertificate
+	return server.ServeTLS(ln, "", "")
+}
+
+func (a *Application) certificateReloader(ctx context.Context, name string, tlsCfg httpTLSConfig) (*certs.Reloader, error) {
+	source := certs.Files(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile)
+	if tlsCfg.VaultPath != "" {
+		client := cfg.GetVaultClient()
+		if client == nil {
+			return nil, ErrHTTPVaultNotAvailable
+		}
+		source = certs.Vault(client, cfg.GetVaultMount(), tlsCfg.VaultPath)
+	}
+	return certs.NewReloader(ctx, name, source, tlsCfg.ReloadInterval)
+}
+
+func (a *Application) createServer(cfg httpServerConfig) *http.Server {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(cfg.HTTP2)
+	protocols.SetUnencryptedHTTP2(cfg.H2C)
+
 	return &http.Server{
-		Addr:         cfg.GetAddr(),
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		IdleTimeout:  cfg.IdleTimeout,
+		Addr:              cfg.GetAddr(),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		Protocols:         &protocols,
 	}
 }
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPListenerConfig(t *testing.T) {
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{
+		"app": map[string]any{
+			"port": 8080,
+			"http": map[string]any{
+				"read_timeout":     "5s",
+				"max_header_bytes": 4096,
+				"tls":              map[string]any{"enabled": true, "cert_file": "tls.crt"},
+			},
+			"listeners": map[string]any{
+				"partners": map[string]any{
+					"port": 8082,
+					"tls":  map[string]any{"enabled": true, "client_ca_file": "ca.crt"},
+				},
+				"public": map[string]any{
+					"port": 8083,
+					"tls":  map[string]any{"enabled": true, "client_ca_file": "ca.crt", "client_auth": "none"},
+				},
+				"internal": map[string]any{
+					"port":          8081,
+					"write_timeout": "30s",
+					"http2":         false,
+					"tls":           map[string]any{"client_auth": "require"},
+				},
+			},
+		},
+	}))
+	config := appConfig{env}
+
+	main := config.GetHTTPServerConfig()
+	assert.Equal(t, ":8080", main.GetAddr())
+	assert.Equal(t, 5*time.Second, main.ReadTimeout)
+	assert.Equal(t, defaultHTTPWriteTimeout, main.WriteTimeout)
+	assert.Equal(t, 4096, main.MaxHeaderBytes)
+	assert.True(t, main.HTTP2)
+	assert.True(t, main.TLS.Enabled)
+
+	// таймауты и лимиты наследуются от основного сервера, TLS нет
+	internal := config.GetHTTPListenerConfig("internal")
+	assert.Equal(t, ":8081", internal.GetAddr())
+	assert.Equal(t, 5*time.Second, internal.ReadTimeout)
+	assert.Equal(t, 30*time.Second, internal.WriteTimeout)
+	assert.Equal(t, 4096, internal.MaxHeaderBytes)
+	assert.False(t, internal.HTTP2)
+	assert.False(t, internal.TLS.Enabled)
+	assert.Empty(t, internal.TLS.CertFile)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, internal.TLS.ClientAuth)
+
+	// CA клиентов без client_auth включает обязательную проверку, явный none ее выключает
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.GetHTTPListenerConfig("partners").TLS.ClientAuth)
+	assert.Equal(t, tls.NoClientCert, config.GetHTTPListenerConfig("public").TLS.ClientAuth)
+
+	assert.Empty(t, config.GetHTTPListenerConfig("unknown").Port)
+}
+
+func TestRegisterListener(t *testing.T) {
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{}))
+	app := &Application{config: appConfig{env}, middlewares: middleware.New(), components: newComponents()}
+
+	var order []string
+	listener := app.RegisterListener("internal", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "router")
+	})).Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "listener")
+			next(w, r)
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	listener.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback", nil))
+	assert.Equal(t, []string{"listener", "router"}, order)
+	// мидлвари по умолчанию применяются и к листенеру
+	assert.NotEmpty(t, rec.Header().Get(headerRequestID))
+
+	// повторная регистрация заменяет роутер
+	assert.Same(t, listener, app.RegisterListener("internal", http.NotFoundHandler()))
+	assert.Len(t, app.listeners, 1)
+}
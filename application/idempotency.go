@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	"git.vepay.dev/knoknok/backend-platform/pkg/idempotency"
+	"google.golang.org/grpc"
+)
+
+const (
+	envIdempotencyTTL     = "idempotency.ttl"
+	envIdempotencyLockTTL = "idempotency.lock_ttl"
+	envIdempotencyPrefix  = "idempotency.prefix"
+)
+
+// WithIdempotency повтор запроса с тем же Idempotency-Key (metadata idempotency-key для gRPC)
+// возвращает сохраненный в redis ответ вместо повторного выполнения. Требует WithRedis.
+// Мидлварь и интерсептор публичного gRPC сервера выполняются после auth.
+// С WithAuth ключи по умолчанию разделяются по auth.Subject, явный idempotency.WithScope это переопределяет
+func WithIdempotency(opts ...idempotency.Option) Option {
+	return func(app *Application) error {
+		if !app.components.add(component(NewComponent(ComponentIdempotency, initIdempotency, Noop, ComponentRedis))) {
+			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, ComponentIdempotency)
+		}
+		app.idempotencyOptions = opts
+		return nil
+	}
+}
+
+func initIdempotency(_ context.Context, app *Application) error {
+	if app.Redis == nil {
+		return errors.New("redis client not initialized")
+	}
+
+	// ключи разных клиентов не пересекаются, опции из кода и конфига применяются позже
+	var opts []idempotency.Option
+	if app.components.has(ComponentAuth) {
+		opts = append(opts, idempotency.WithScope(auth.Subject))
+	}
+	// значения из конфига перекрывают опции из кода
+	opts = append(opts, app.idempotencyOptions...)
+	if ttl := app.Env.GetDuration(envIdempotencyTTL); ttl > 0 {
+		opts = append(opts, idempotency.WithTTL(ttl))
+	}
+	if ttl := app.Env.GetDuration(envIdempotencyLockTTL); ttl > 0 {
+		opts = append(opts, idempotency.WithLockTTL(ttl))
+	}
+	if prefix := app.Env.GetString(envIdempotencyPrefix); prefix != "" {
+		opts = append(opts, idempotency.WithPrefix(prefix))
+	}
+	app.idempotency = idempotency.NewStore(app.Redis, opts...)
+	return nil
+}
+
+// idempotencyMiddleware хранилище создается в init компонента, мидлварь добавляется раньше, после auth и ratelimit
+func (a *Application) idempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.idempotency == nil {
+			next(w, r)
+			return
+		}
+		idempotency.Middleware(a.idempotency)(next)(w, r)
+	}
+}
+
+// idempotencyUnaryInterceptor хранилище создается в init компонента, интерсептор добавляется раньше, после auth и ratelimit
+func (a *Application) idempotencyUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if a.idempotency == nil {
+		return handler(ctx, req)
+	}
+	return idempotency.UnaryServerInterceptor(a.idempotency)(ctx, req, info, handler)
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	"git.vepay.dev/knoknok/backend-platform/pkg/idempotency"
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// keysRedis запоминает ключи, с которыми вызываются lua скрипты
+type keysRedis struct {
+	redis.Redis
+	keys []string
+}
+
+func (r *keysRedis) Eval(_ context.Context, _ string, keys []string, _ ...any) (any, error) {
+	r.keys = append(r.keys, keys...)
+	return "", nil
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	begin := func(secured bool, opts ...idempotency.Option) string {
+		env := cfg.New("", "")
+		require.NoError(t, env.LoadEnvMap(map[string]any{}))
+		app := &Application{config: appConfig{env}, Env: env, Closer: closers.New(),
+			middlewares: middleware.New(), components: newComponents()}
+		if secured {
+			require.NoError(t, WithAuth(nil)(app))
+		}
+		require.NoError(t, WithIdempotency(opts...)(app))
+		rdb := &keysRedis{}
+		app.Redis = rdb
+		require.NoError(t, initIdempotency(context.Background(), app))
+
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "user-1"})
+		_, _, err := app.idempotency.Begin(ctx, "k1", "fp")
+		require.NoError(t, err)
+		require.Len(t, rdb.keys, 1)
+		return rdb.keys[0]
+	}
+
+	// с WithAuth ключи разных клиентов не пересекаются
+	assert.Contains(t, begin(true), "user-1:k1")
+	assert.NotContains(t, begin(false), "user-1")
+	// явная область из опций важнее
+	assert.Contains(t, begin(true, idempotency.WithScope(func(context.Context) string { return "tenant" })), "tenant:k1")
+}
+
+func TestIdempotencyGrpcAfterAuth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), "k1"))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "user-1", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}).Serialize()
+	require.NoError(t, err)
+
+	app := newGrpcTestApp(t)
+	// идемпотентность до auth и сервера: интерсептор все равно выполняется после auth
+	require.NoError(t, WithIdempotency()(app))
+	require.NoError(t, WithAuth(nil)(app))
+	require.NoError(t, WithPublicGrpcServer(registerTestPayments, &testPayments{})(app))
+	rdb := &keysRedis{}
+	app.Redis = rdb
+	require.NoError(t, initIdempotency(ctx, app))
+	app.authVerifier = auth.NewVerifier(auth.NewStaticKeys(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1"}}}), auth.Config{})
+	app.addComponentInterceptors()
+	conn := serveGrpc(ctx, t, app)
+
+	md := metadata.Pairs("authorization", "Bearer "+token, "idempotency-key", "k1")
+	err = conn.Invoke(metadata.NewOutgoingContext(ctx, md), testPaymentsCreate, wrapperspb.String("1"), &wrapperspb.StringValue{})
+	require.NoError(t, err)
+	require.NotEmpty(t, rdb.keys)
+	assert.Contains(t, rdb.keys[0], "user-1:k1")
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"git.vepay.dev/knoknok/backend-platform/pkg/kafka"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 )
 
 var (
-	kafkaComponent = NewComponent("kafka", initKafkaClient, runKafkaClient)
+	kafkaComponent = NewComponent(ComponentKafka, initKafkaClient, runKafkaClient, ComponentDI)
 )
 
 // WithKafka add kafka client component, available
@@ -34,8 +35,10 @@ func initKafkaClient(ctx context.Context, app *Application) error {
 		return err
 	}
 
-	app.Closer.Add(client.Close)
+	app.Closer.Add("kafka-consumers", client.StopConsumers, closers.WithPhase(closers.PhaseServers))
+	app.Closer.Add(ComponentKafka, closers.Wrap(client.Close))
 	app.Health.Add("kafka", client.HealthCheck)
+	app.Readiness.Add("kafka", client.Ready)
 	app.Kafka = client
 
 	di.Register(ctx, app.Kafka)
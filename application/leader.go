@@ -0,0 +1,231 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/lock"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+const (
+	defaultLeaderTTL    = 15 * time.Second
+	leaderResignTimeout = 5 * time.Second
+)
+
+// LeaderFunc обработчик смены лидерства
+type LeaderFunc func(ctx context.Context)
+
+type LeaderOption func(*elector)
+
+// LeaderKey ключ блокировки, по умолчанию leader:<app.name>
+func LeaderKey(key string) LeaderOption {
+	return func(e *elector) {
+		e.key = key
+	}
+}
+
+// LeaderTTL время аренды лидерства, продление и попытки захвата каждые ttl/3, по умолчанию 15s
+func LeaderTTL(ttl time.Duration) LeaderOption {
+	return func(e *elector) {
+		e.ttl = ttl
+	}
+}
+
+type elector struct {
+	key     string
+	ttl     time.Duration
+	backend LockBackend
+	locker  lock.Locker
+
+	mu        sync.Mutex
+	onElected []LeaderFunc
+	onRevoked []LeaderFunc
+
+	leader       atomic.Bool
+	held         lock.Lock
+	leaderCancel context.CancelFunc
+	callbacks    sync.WaitGroup
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WithLeaderElection выбор лидера среди реплик через аренду в redis или pg_try_advisory_lock в postgres.
+// Состояние доступно через app.IsLeader, переходы - через app.OnElected и app.OnRevoked
+func WithLeaderElection(backend LockBackend, opts ...LeaderOption) Option {
+	return func(app *Application) error {
+		e := app.leader
+		e.backend = backend
+		for _, opt := range opts {
+			opt(e)
+		}
+
+		run := func(ctx context.Context, app *Application) error {
+			return runElector(ctx, app, e)
+		}
+		if !app.components.add(component{name: ComponentLeader, initFn: Noop, runFn: run, deps: []string{string(backend)}}) {
+			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, ComponentLeader)
+		}
+		return nil
+	}
+}
+
+func newElector() *elector {
+	return &elector{
+		ttl:  defaultLeaderTTL,
+		done: make(chan struct{}),
+	}
+}
+
+// IsLeader true если реплика сейчас лидер, без WithLeaderElection всегда false
+func (a *Application) IsLeader() bool {
+	return a.leader.leader.Load()
+}
+
+// OnElected регистрирует обработчик получения лидерства, обработчик запускается в отдельной горутине,
+// ctx отменяется при потере лидерства или остановке приложения
+func (a *Application) OnElected(fn LeaderFunc) {
+	a.leader.mu.Lock()
+	defer a.leader.mu.Unlock()
+	a.leader.onElected = append(a.leader.onElected, fn)
+}
+
+// OnRevoked регистрирует обработчик потери лидерства,
+// вызывается после завершения обработчиков OnElected
+func (a *Application) OnRevoked(fn LeaderFunc) {
+	a.leader.mu.Lock()
+	defer a.leader.mu.Unlock()
+	a.leader.onRevoked = append(a.leader.onRevoked, fn)
+}
+
+func runElector(ctx context.Context, app *Application, e *elector) error {
+	locker, err := e.backend.locker(app)
+	if err != nil {
+		return fmt.Errorf("leader election: %w", err)
+	}
+	e.locker = locker
+	if e.key == "" {
+		e.key = "leader:" + app.config.GetAppName()
+	}
+	metrics.LeaderElectionIsLeader.WithLabelValues(e.key).Set(0)
+
+	ctx, e.cancel = context.WithCancel(logger.With(ctx, logger.String("leader_key", e.key)))
+	app.Closer.Add(ComponentLeader, e.Stop)
+
+	go e.loop(ctx)
+	return nil
+}
+
+// loop пытается захватить лидерство, а захватив - продлевает аренду
+func (e *elector) loop(ctx context.Context) {
+	defer close(e.done)
+
+	t := time.NewTicker(e.ttl / 3)
+	defer t.Stop()
+
+	for {
+		e.step(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.resign(ctx)
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (e *elector) step(ctx context.Context) {
+	if e.held != nil {
+		if err := e.held.Refresh(ctx, e.ttl); err != nil && ctx.Err() == nil {
+			logger.Warn(ctx, "Leadership lost", logger.Err(err))
+			e.revoke(ctx)
+			e.held = nil
+		}
+		return
+	}
+
+	held, err := e.locker.TryLock(ctx, e.key, e.ttl)
+	if errors.Is(err, lock.ErrNotAcquired) || ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		logger.Error(ctx, "Leader election failed", logger.Err(err))
+		return
+	}
+	e.held = held
+	e.elect(ctx)
+}
+
+func (e *elector) elect(ctx context.Context) {
+	var leaderCtx context.Context
+	leaderCtx, e.leaderCancel = context.WithCancel(ctx)
+	e.leader.Store(true)
+	metrics.LeaderElectionIsLeader.WithLabelValues(e.key).Set(1)
+	logger.Info(ctx, "Leadership acquired")
+
+	e.mu.Lock()
+	handlers := e.onElected
+	e.mu.Unlock()
+
+	for _, fn := range handlers {
+		e.callbacks.Add(1)
+		go func() {
+			defer e.callbacks.Done()
+			fn(leaderCtx)
+		}()
+	}
+}
+
+// revoke отменяет контекст обработчиков OnElected, ждет их завершения и вызывает OnRevoked
+func (e *elector) revoke(ctx context.Context) {
+	e.leader.Store(false)
+	metrics.LeaderElectionIsLeader.WithLabelValues(e.key).Set(0)
+	e.leaderCancel()
+	e.callbacks.Wait()
+
+	e.mu.Lock()
+	handlers := e.onRevoked
+	e.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(ctx)
+	}
+	logger.Info(ctx, "Leadership revoked")
+}
+
+// resign освобождает лидерство при остановке, чтобы другая реплика не ждала истечения аренды
+func (e *elector) resign(ctx context.Context) {
+	if e.held == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaderResignTimeout)
+	defer cancel()
+
+	e.revoke(ctx)
+	if err := e.held.Unlock(ctx); err != nil {
+		logger.Warn(ctx, "Leadership release failed", logger.Err(err))
+	}
+	e.held = nil
+}
+
+// Stop снимает лидерство и ждет завершения обработчиков
+func (e *elector) Stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderElection(t *testing.T) {
+	locker := &memLocker{held: map[string]bool{}}
+	ctx := context.Background()
+
+	first := newElector()
+	first.key, first.locker = "leader:test", locker
+	second := newElector()
+	second.key, second.locker = "leader:test", locker
+
+	var handoverDone, revoked atomic.Bool
+	first.onElected = []LeaderFunc{func(ctx context.Context) {
+		<-ctx.Done()
+		handoverDone.Store(true)
+	}}
+	first.onRevoked = []LeaderFunc{func(context.Context) {
+		revoked.Store(handoverDone.Load())
+	}}
+
+	first.step(ctx)
+	second.step(ctx)
+	assert.True(t, first.leader.Load())
+	assert.False(t, second.leader.Load())
+
+	// при остановке обработчики OnElected завершаются до OnRevoked, блокировка освобождается
+	first.resign(ctx)
+	assert.False(t, first.leader.Load())
+	assert.True(t, revoked.Load())
+
+	second.step(ctx)
+	assert.True(t, second.leader.Load())
+}
+
+func TestIsLeaderWithoutElection(t *testing.T) {
+	app := &Application{leader: newElector()}
+	assert.False(t, app.IsLeader())
+}
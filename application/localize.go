@@ -17,7 +17,7 @@ import (
 )
 
 var (
-	localizeComponent = NewComponent("localize", initLocalizeClient, Noop)
+	localizeComponent = NewComponent(ComponentLocalize, initLocalizeClient, Noop, ComponentDI)
 )
 
 const (
@@ -124,7 +124,7 @@ func runTranslationsWatcher(
 	}
 
 	cancel := app.translateManager.Watch(ctx, loader, interval)
-	app.Closer.Add(func() error {
+	app.Closer.Add(ComponentLocalize, func(context.Context) error {
 		cancel()
 		return nil
 	})
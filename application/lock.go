@@ -0,0 +1,37 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/lock"
+)
+
+var (
+	ErrLockBackendUnavailable = errors.New("lock backend unavailable")
+)
+
+// LockBackend хранилище распределенных блокировок, значение совпадает с названием компонента
+type LockBackend string
+
+const (
+	LockRedis    LockBackend = ComponentRedis
+	LockPostgres LockBackend = ComponentPostgres
+)
+
+// locker создает блокировки на клиенте приложения, компонент должен быть уже инициализирован
+func (b LockBackend) locker(app *Application) (lock.Locker, error) {
+	switch b {
+	case LockRedis:
+		if app.Redis != nil {
+			return lock.NewRedis(app.Redis), nil
+		}
+	case LockPostgres:
+		if app.DB != nil {
+			return lock.NewPostgres(app.DB), nil
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown backend %q", ErrLockBackendUnavailable, string(b))
+	}
+	return nil, fmt.Errorf("%w: %s is not initialized", ErrLockBackendUnavailable, string(b))
+}
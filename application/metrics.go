@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -9,7 +10,7 @@ import (
 )
 
 var (
-	metricsComponent = NewComponent("metrics", initMetrics, runMetrics)
+	metricsComponent = NewComponent(ComponentMetrics, initMetrics, runMetrics)
 )
 
 func WithMetrics() Option {
@@ -38,15 +39,11 @@ func runMetrics(ctx context.Context, app *Application) error {
 		Handler: mux,
 	}
 
-	app.Closer.Add(func() error {
-		logger.Info(ctx, "Metrics server shutting down")
-		return server.Shutdown(ctx)
-	})
+	app.Closer.Add(ComponentMetrics, server.Shutdown, closers.WithPhase(closers.PhaseTelemetry))
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error(ctx, "Metrics server failed", logger.Err(err))
-			app.stop()
+			app.Fail(ComponentMetrics, err)
 		}
 	}()
 
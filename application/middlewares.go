@@ -3,13 +3,20 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
 	"net/http"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/httproute"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/trace"
+	"github.com/google/uuid"
 )
 
 func noopHandler() http.HandlerFunc {
@@ -23,6 +30,8 @@ type HealthResponse struct {
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
 	Code      int       `json:"code"`
+	// Checks результаты health check, в ответе пробы только при verbose
+	Checks []health.Result `json:"checks,omitempty"`
 }
 
 func checkReadiness(ctx context.Context, a *Application) HealthResponse {
@@ -42,16 +51,33 @@ func checkReadiness(ctx context.Context, a *Application) HealthResponse {
 
 	select {
 	case <-a.started:
-		// app was started, check health
-		if err := a.Health.Check(ctx); err != nil {
-			logger.Error(ctx, "Application got health check error", logger.Err(err))
+		// app was started, check components are ready and healthy
+		if err := a.Readiness.Check(ctx); err != nil {
+			logger.Warn(ctx, "Application is waiting for component", logger.Err(err))
 			response.Status = "not_ready"
-			response.Message = "Application has problems"
+			response.Message = waitingMessage(err)
 			response.Code = http.StatusServiceUnavailable
 		} else {
-			response.Status = "ready"
-			response.Message = "Application is ready to accept requests"
-			response.Code = http.StatusOK
+			report := a.Health.Report(ctx)
+			response.Checks = report.Checks
+			switch report.Status {
+			case health.StatusDown:
+				failed := strings.Join(report.Failed(true), ", ")
+				logger.Error(ctx, "Application got health check error", logger.String("checks", failed))
+				response.Status = "not_ready"
+				response.Message = "Application has problems: " + failed
+				response.Code = http.StatusServiceUnavailable
+			case health.StatusDegraded:
+				failed := strings.Join(report.Failed(false), ", ")
+				logger.Warn(ctx, "Application optional checks failed", logger.String("checks", failed))
+				response.Status = "degraded"
+				response.Message = "Application is ready, optional checks failed: " + failed
+				response.Code = http.StatusOK
+			default:
+				response.Status = "ready"
+				response.Message = "Application is ready to accept requests"
+				response.Code = http.StatusOK
+			}
 		}
 	case <-ctx.Done():
 		response.Status = "not_ready"
@@ -61,6 +87,47 @@ func checkReadiness(ctx context.Context, a *Application) HealthResponse {
 	return response
 }
 
+// checkStartup приложение запущено и все условия готовности выполнились хотя бы раз,
+// после этого startup-проба всегда успешна, дальнейшие проблемы обрабатывает readiness
+func checkStartup(ctx context.Context, a *Application) HealthResponse {
+	response := HealthResponse{
+		Timestamp: time.Now(),
+		Status:    "started",
+		Message:   "Application has started",
+		Code:      http.StatusOK,
+	}
+	if a.startupDone.Load() {
+		return response
+	}
+
+	select {
+	case <-a.started:
+		if err := a.Readiness.Check(ctx); err != nil {
+			response.Status = "starting"
+			response.Message = waitingMessage(err)
+			response.Code = http.StatusServiceUnavailable
+			return response
+		}
+		a.startupDone.Store(true)
+		logger.Info(ctx, "Application startup completed")
+	default:
+		response.Status = "starting"
+		response.Message = "Application is still starting up"
+		response.Code = http.StatusServiceUnavailable
+	}
+
+	return response
+}
+
+// waitingMessage название компонента, условие готовности которого не выполнено
+func waitingMessage(err error) string {
+	var checkErr *health.CheckError
+	if errors.As(err, &checkErr) {
+		return "Application is waiting for " + checkErr.Name + ": " + checkErr.Err.Error()
+	}
+	return "Application is waiting for components: " + err.Error()
+}
+
 // livenessMiddleware return application is alive
 func (a *Application) livenessMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -88,6 +155,9 @@ func (a *Application) readinessMiddleware(next http.HandlerFunc) http.HandlerFun
 			ctx, cancel := context.WithTimeout(r.Context(), defaultReadinessTimeout)
 			defer cancel()
 			response := checkReadiness(ctx, a)
+			if !a.healthzVerbose(r) {
+				response.Checks = nil
+			}
 			w.WriteHeader(response.Code)
 			json.NewEncoder(w).Encode(response)
 			return
@@ -96,6 +166,164 @@ func (a *Application) readinessMiddleware(next http.HandlerFunc) http.HandlerFun
 	}
 }
 
+// healthzVerbose результаты проверок в ответе readiness только при app.healthz.verbose,
+// ?verbose=false их скрывает, но не может включить: пробы доступны без авторизации, детали есть в /debug/health
+func (a *Application) healthzVerbose(r *http.Request) bool {
+	if !a.Env.GetBool(envHealthzVerbose) {
+		return false
+	}
+	verbose, err := strconv.ParseBool(r.URL.Query().Get("verbose"))
+	return err != nil || verbose
+}
+
+// startupMiddleware return application is started and all components are ready, for k8s startupProbe
+func (a *Application) startupMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz/startup" {
+			w.Header().Set("Content-Type", "application/json")
+			ctx, cancel := context.WithTimeout(r.Context(), defaultReadinessTimeout)
+			defer cancel()
+			response := checkStartup(ctx, a)
+			w.WriteHeader(response.Code)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// addDefaultMiddlewares мидлвари http-сервера по умолчанию, выполняются до проб и роутера:
+// трассировка (при WithTrace), request id, метрики, перехват паники. Отключаются через app.middlewares.*
+func (a *Application) addDefaultMiddlewares(m middleware.Middlewares) {
+	cfg := a.config.GetHTTPMiddlewaresConfig()
+	// спан охватывает всю цепочку, паника попадает в него как 500 после recovery
+	if cfg.Trace && a.components.has(ComponentTrace) {
+		m.Add(trace.MiddlewareHTTP(func(r *http.Request) bool {
+			return !isMetricPath(r.URL.Path)
+		}))
+	}
+	if cfg.RequestID {
+		m.Add(a.requestIDMiddleware)
+	}
+	// метрики снаружи recovery, чтобы паника попала в http_requests_total как 500
+	if cfg.Metrics {
+		m.Add(a.httpMetricsMiddleware)
+	}
+	if cfg.Recovery {
+		m.Add(a.recoveryMiddleware)
+	}
+}
+
+// addComponentMiddlewares мидлвари компонентов в фиксированном порядке: auth, ratelimit, idempotency, swagger.
+// Компоненты создаются в init параллельно, поэтому мидлвари добавляются до init, а не по завершении init:
+// иначе порядок зависел бы от того, какой init закончится первым
+func (a *Application) addComponentMiddlewares(m middleware.Middlewares) {
+	if a.components.has(ComponentAuth) {
+		m.Add(a.authMiddleware)
+	}
+	if a.components.has(ComponentRateLimit) {
+		m.Add(a.rateLimitMiddleware)
+	}
+	if a.components.has(ComponentIdempotency) {
+		m.Add(a.idempotencyMiddleware)
+	}
+	if a.components.has(ComponentSwagger) {
+		m.Add(a.swaggerMiddleware)
+	}
+}
+
+// addComponentInterceptors интерсепторы компонентов gRPC серверов в том же порядке, что и мидлвари.
+// Добавляются после всех опций, поэтому не зависят от того, указан ли WithAuth до или после WithPublicGrpcServer
+func (a *Application) addComponentInterceptors() {
+	for _, server := range a.grpcServers() {
+		if a.components.has(ComponentAuth) {
+			server.AddUnaryInterceptor(a.authUnaryInterceptor)
+			server.AddStreamInterceptor(a.authStreamInterceptor)
+		}
+	}
+	// лимиты и идемпотентность только на публичном сервере
+	if a.PublicGrpcServer == nil {
+		return
+	}
+	if a.components.has(ComponentRateLimit) {
+		a.PublicGrpcServer.AddUnaryInterceptor(a.rateLimitUnaryInterceptor)
+		a.PublicGrpcServer.AddStreamInterceptor(a.rateLimitStreamInterceptor)
+	}
+	// после auth, иначе область ключей auth.Subject пустая и ответы одного клиента доступны другому
+	if a.components.has(ComponentIdempotency) {
+		a.PublicGrpcServer.AddUnaryInterceptor(a.idempotencyUnaryInterceptor)
+	}
+}
+
+// Request id
+
+const (
+	headerRequestID    = "X-Request-Id"
+	maxRequestIDLength = 128
+)
+
+// requestIDMiddleware берет X-Request-Id из запроса или генерирует новый,
+// кладет его в контекст как correlationId для логов и возвращает в ответе
+func (a *Application) requestIDMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+			r.Header.Set(headerRequestID, requestID)
+		}
+
+		w.Header().Set(headerRequestID, requestID)
+		ctx := context.WithValue(r.Context(), logger.CorrelationId, requestID)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// validRequestID ограничивает длину и набор символов, значение попадает в логи и заголовки
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Recovery
+
+// recoveryMiddleware перехватывает панику обработчика, логирует стек и отвечает 500.
+// http.ErrAbortHandler пробрасывается дальше, им обработчик намеренно обрывает соединение
+func (a *Application) recoveryMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			logger.Error(r.Context(), "HTTP handler panic",
+				logger.String("method", r.Method),
+				logger.String("path", r.URL.Path),
+				logger.Any("panic", p),
+				logger.String("stack", string(debug.Stack())),
+			)
+			metrics.HTTPPanicsTotal.WithLabelValues(r.Method, a.normalizePath(r)).Inc()
+
+			// если ответ уже начат, статус изменить нельзя
+			if rec.status == 0 {
+				http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next(rec, r)
+	}
+}
+
 // Metrics
 
 type statusRecorder struct {
@@ -112,6 +340,9 @@ func (a *Application) httpMetricsMiddleware(next http.HandlerFunc) http.HandlerF
 			return
 		}
 
+		// шаблон маршрута заполняет роутер, см. pkg/httproute
+		r = r.WithContext(httproute.WithHolder(r.Context()))
+
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w}
 		next(rec, r)
@@ -122,7 +353,7 @@ func (a *Application) httpMetricsMiddleware(next http.HandlerFunc) http.HandlerF
 		}
 
 		method := r.Method
-		path := normalizePath(r)
+		path := a.normalizePath(r)
 		status := strconv.Itoa(rec.status)
 		t := time.Since(start).Seconds()
 
@@ -135,9 +366,14 @@ func isMetricPath(p string) bool {
 	return p == "/metrics" || strings.HasPrefix(p, "/healthz/")
 }
 
-func normalizePath(r *http.Request) string {
-	// TODO для нормализации параметров
-	return r.URL.Path
+// normalizePath шаблон маршрута вместо пути, чтобы /users/123 и /users/456 были одной серией.
+// Запросы без шаблона (404, роутер без адаптера httproute) и сверх лимита различных шаблонов - other
+func (a *Application) normalizePath(r *http.Request) string {
+	pattern := httproute.Get(r.Context())
+	if pattern == "" {
+		pattern = httproute.PatternPath(r.Pattern)
+	}
+	return a.routes.Label(pattern)
 }
 
 func (w *statusRecorder) WriteHeader(code int) {
@@ -145,6 +381,11 @@ func (w *statusRecorder) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap для http.ResponseController: Flush, Hijack и дедлайны исходного writer
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *statusRecorder) Write(b []byte) (int, error) {
 	// если WriteHeader ещё не вызывали — считаем 200
 	if w.status == 0 {
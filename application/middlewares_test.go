@@ -0,0 +1,130 @@
+package application
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/httproute"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := &Application{middlewares: middleware.New()}
+	app.middlewares.Add(app.requestIDMiddleware)
+
+	var correlationID any
+	app.RegisterRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID = r.Context().Value(logger.CorrelationId)
+	}))
+
+	// переданный id пробрасывается в контекст и ответ
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(headerRequestID, "req-1")
+	app.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, "req-1", rec.Header().Get(headerRequestID))
+	assert.Equal(t, "req-1", correlationID)
+
+	// некорректный id заменяется сгенерированным
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(headerRequestID, "bad id")
+	app.Handler().ServeHTTP(rec, req)
+	generated := rec.Header().Get(headerRequestID)
+	assert.NotEqual(t, "bad id", generated)
+	assert.Len(t, generated, 36)
+	assert.Equal(t, generated, correlationID)
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	app := &Application{middlewares: middleware.New()}
+	app.middlewares.Add(app.recoveryMiddleware)
+
+	app.RegisterRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// ErrAbortHandler обрывает соединение, его обрабатывает net/http
+	app.RegisterRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	})
+}
+
+func TestDefaultMiddlewaresFlush(t *testing.T) {
+	app := &Application{middlewares: middleware.New(), routes: httproute.NewLimiter(0)}
+	app.middlewares.Add(app.httpMetricsMiddleware)
+	app.middlewares.Add(app.recoveryMiddleware)
+
+	// SSE и websocket получают исходный writer через http.ResponseController
+	var flushErr error
+	app.RegisterRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: ping\n\n"))
+		flushErr = http.NewResponseController(w).Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.NoError(t, flushErr)
+	assert.True(t, rec.Flushed)
+}
+
+func TestHTTPMetricsRouteLabel(t *testing.T) {
+	app := &Application{middlewares: middleware.New(), routes: httproute.NewLimiter(1)}
+	app.middlewares.Add(app.httpMetricsMiddleware)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics-test/users/{id}", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("GET /metrics-test/orders/{id}", func(http.ResponseWriter, *http.Request) {})
+	app.RegisterRouter(mux)
+
+	counter := func(path, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues("GET", path, status))
+	}
+	// счетчики глобальные, сравниваем прирост, чтобы тест проходил с -count
+	users := counter("/metrics-test/users/{id}", "200")
+	otherOK, otherNotFound := counter(httproute.Other, "200"), counter(httproute.Other, "404")
+
+	for _, path := range []string{"/metrics-test/users/1", "/metrics-test/users/2", "/metrics-test/orders/1", "/metrics-test/unknown"} {
+		app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, counter("/metrics-test/users/{id}", "200")-users)
+	// второй шаблон сверх лимита
+	assert.Equal(t, 1.0, counter(httproute.Other, "200")-otherOK)
+	assert.Equal(t, 1.0, counter(httproute.Other, "404")-otherNotFound)
+}
+
+func TestHealthzVerbose(t *testing.T) {
+	newApp := func(verbose bool) *Application {
+		env := cfg.New("", "")
+		require.NoError(t, env.LoadEnvMap(map[string]any{"app": map[string]any{"healthz": map[string]any{"verbose": verbose}}}))
+		return &Application{Env: env}
+	}
+	request := func(query string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/healthz/ready"+query, nil)
+	}
+
+	// по умолчанию детали не отдаются, query параметр их не включает
+	app := newApp(false)
+	assert.False(t, app.healthzVerbose(request("")))
+	assert.False(t, app.healthzVerbose(request("?verbose=true")))
+
+	app = newApp(true)
+	assert.True(t, app.healthzVerbose(request("")))
+	assert.True(t, app.healthzVerbose(request("?verbose=true")))
+	assert.False(t, app.healthzVerbose(request("?verbose=false")))
+}
@@ -11,11 +11,22 @@ var (
 	ErrComponentAlreadyExist = errors.New("component already exist")
 )
 
-func WithComponent(name string, init, run ComponentFunc) Option {
+// WithComponent добавляет пользовательский компонент,
+// deps - названия компонентов, от которых он зависит (например ComponentPostgres, ComponentRedis)
+func WithComponent(name string, init, run ComponentFunc, deps ...string) Option {
 	return func(app *Application) error {
-		if !app.components.add(component{name: name, initFn: init, runFn: run}) {
+		if !app.components.add(component{name: name, initFn: init, runFn: run, deps: deps}) {
 			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, name)
 		}
 		return nil
 	}
 }
+
+// WithTestMode режим для интеграционных тестов: http и admin серверы не слушают порт,
+// сигналы ОС не перехватываются, остановка через app.Shutdown
+func WithTestMode() Option {
+	return func(app *Application) error {
+		app.testMode = true
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/ratelimit"
+	"google.golang.org/grpc"
+)
+
+const (
+	envRateLimitEnabled    = "ratelimit.enabled"
+	envRateLimitFailClosed = "ratelimit.fail_closed"
+	envRateLimitPrefix     = "ratelimit.prefix"
+	envRateLimitRules      = "ratelimit.rules."
+)
+
+// rateLimitConfig лимиты правил из конфига, обновляются без перезапуска
+type rateLimitConfig struct {
+	Enabled bool
+	Rules   map[string]rateLimitRuleConfig
+}
+
+type rateLimitRuleConfig struct {
+	Enabled bool
+	Limit   ratelimit.Limit
+}
+
+func newRateLimitConfig(rules []ratelimit.Rule) func(config.Configurer) rateLimitConfig {
+	return func(env config.Configurer) rateLimitConfig {
+		cfg := rateLimitConfig{
+			Enabled: env.GetBoolOrDefault(envRateLimitEnabled, true),
+			Rules:   make(map[string]rateLimitRuleConfig, len(rules)),
+		}
+		for _, rule := range rules {
+			key := envRateLimitRules + rule.Name
+			cfg.Rules[rule.Name] = rateLimitRuleConfig{
+				Enabled: env.GetBoolOrDefault(key+".enabled", true),
+				Limit: ratelimit.Limit{
+					Rate:   env.GetIntOrDefault(key+".rate", rule.Limit.Rate),
+					Period: getDurationOrDefault(env.GetDuration(key+".period"), rule.Limit.Period),
+					Burst:  env.GetIntOrDefault(key+".burst", rule.Limit.Burst),
+				},
+			}
+		}
+		return cfg
+	}
+}
+
+// WithRateLimit ограничение частоты запросов к основному http-серверу и публичному gRPC серверу.
+// Правила с Distributed считаются в redis и требуют WithRedis, остальные - в памяти реплики.
+// Лимиты переопределяются в consul: ratelimit.rules.<name>.rate/period/burst/enabled.
+// Все правила передаются одним вызовом, повторный WithRateLimit возвращает ErrComponentAlreadyExist
+func WithRateLimit(rules ...ratelimit.Rule) Option {
+	return func(app *Application) error {
+		for _, rule := range rules {
+			if rule.Name == "" || rule.Key == nil {
+				return errors.New("rate limit rule requires name and key")
+			}
+		}
+
+		var deps []string
+		if slices.ContainsFunc(rules, func(r ratelimit.Rule) bool { return r.Distributed }) {
+			deps = append(deps, ComponentRedis)
+		}
+		if !app.components.add(component(NewComponent(ComponentRateLimit, initRateLimit, Noop, deps...))) {
+			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, ComponentRateLimit)
+		}
+		app.rateLimitRules = rules
+		return nil
+	}
+}
+
+func initRateLimit(ctx context.Context, app *Application) error {
+	cfg := config.NewConfigWatcher("ratelimit", app.Env, newRateLimitConfig(app.rateLimitRules))
+	app.Env.Subscribe(cfg)
+
+	opts := []ratelimit.Option{
+		ratelimit.WithLimits(func(rule ratelimit.Rule) (ratelimit.Limit, bool) {
+			current := cfg.Get()
+			ruleCfg := current.Rules[rule.Name]
+			return ruleCfg.Limit, current.Enabled && ruleCfg.Enabled
+		}),
+	}
+	if app.Redis != nil {
+		opts = append(opts, ratelimit.WithBackend(ratelimit.NewRedis(app.Redis, app.Env.GetString(envRateLimitPrefix))))
+	}
+	if app.Env.GetBool(envRateLimitFailClosed) {
+		opts = append(opts, ratelimit.WithFailClosed())
+	}
+	app.rateLimiter = ratelimit.New(app.rateLimitRules, opts...)
+
+	for _, rule := range app.rateLimitRules {
+		limit := cfg.Get().Rules[rule.Name].Limit
+		logger.Info(ctx, "Rate limit rule",
+			logger.String("rule", rule.Name),
+			logger.Int("rate", limit.Rate),
+			logger.Duration("period", limit.Period),
+			logger.Bool("distributed", rule.Distributed),
+		)
+	}
+	return nil
+}
+
+// rateLimitMiddleware лимитер создается в init компонента, мидлварь добавляется раньше, после проб и auth
+func (a *Application) rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.rateLimiter == nil {
+			next(w, r)
+			return
+		}
+		a.rateLimiter.Middleware()(next)(w, r)
+	}
+}
+
+// rateLimitUnaryInterceptor лимитер создается в init компонента, интерсептор добавляется раньше
+func (a *Application) rateLimitUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if a.rateLimiter == nil {
+		return handler(ctx, req)
+	}
+	return a.rateLimiter.UnaryServerInterceptor()(ctx, req, info, handler)
+}
+
+func (a *Application) rateLimitStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if a.rateLimiter == nil {
+		return handler(srv, ss)
+	}
+	return a.rateLimiter.StreamServerInterceptor()(srv, ss, info, handler)
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cfg "git.vepay.dev/knoknok/backend-platform/internal/pkg/config"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/middleware"
+	"git.vepay.dev/knoknok/backend-platform/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRateLimit(t *testing.T) {
+	env := cfg.New("", "")
+	require.NoError(t, env.LoadEnvMap(map[string]any{
+		"ratelimit": map[string]any{
+			"rules": map[string]any{
+				"login":  map[string]any{"rate": 1, "period": "1m"},
+				"search": map[string]any{"enabled": false},
+			},
+		},
+	}))
+	app := &Application{config: appConfig{env}, Env: env, middlewares: middleware.New(), components: newComponents()}
+
+	require.NoError(t, WithRateLimit(
+		ratelimit.Rule{Name: "login", Match: ratelimit.PathPrefix("/login"), Key: ratelimit.ByIP(),
+			Limit: ratelimit.Limit{Rate: 10, Period: time.Second}},
+		ratelimit.Rule{Name: "search", Match: ratelimit.PathPrefix("/search"), Key: ratelimit.ByIP(),
+			Limit: ratelimit.Limit{Rate: 1, Period: time.Minute}},
+	)(app))
+	require.NoError(t, initRateLimit(context.Background(), app))
+	app.addComponentMiddlewares(app.middlewares)
+
+	handler := app.middlewares.Chain()(func(w http.ResponseWriter, r *http.Request) {})
+	call := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	// лимит из конфига перекрывает значение в коде
+	assert.Equal(t, http.StatusOK, call("/login"))
+	assert.Equal(t, http.StatusTooManyRequests, call("/login"))
+
+	// отключенное в конфиге правило не применяется
+	assert.Equal(t, http.StatusOK, call("/search"))
+	assert.Equal(t, http.StatusOK, call("/search"))
+
+	assert.Error(t, WithRateLimit(ratelimit.Rule{Name: "broken"})(app))
+	// второй вызов не перезаписывает правила и не добавляет интерсепторы повторно
+	assert.ErrorIs(t, WithRateLimit(ratelimit.Rule{Name: "api", Key: ratelimit.ByIP()})(app), ErrComponentAlreadyExist)
+}
+
+func TestRateLimitGrpcInterceptors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	app := newGrpcTestApp(t)
+	// WithRateLimit до WithPublicGrpcServer: интерсептор добавляется после всех опций
+	require.NoError(t, WithRateLimit(ratelimit.Rule{Name: "create", Key: ratelimit.ByMethod(),
+		Limit: ratelimit.Limit{Rate: 1, Period: time.Minute}})(app))
+	require.NoError(t, WithPublicGrpcServer(registerTestPayments, &testPayments{})(app))
+	require.NoError(t, initRateLimit(ctx, app))
+	app.addComponentInterceptors()
+	conn := serveGrpc(ctx, t, app)
+
+	call := func() codes.Code {
+		return status.Code(conn.Invoke(ctx, testPaymentsCreate, wrapperspb.String("1"), &wrapperspb.StringValue{}))
+	}
+	assert.Equal(t, codes.OK, call())
+	assert.Equal(t, codes.ResourceExhausted, call())
+}
@@ -2,14 +2,18 @@ package application
 
 import (
 	"context"
+	"errors"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
 )
 
 var (
-	redisComponent = NewComponent("redis", initRedisClient, Noop)
+	redisComponent = NewComponent(ComponentRedis, initRedisClient, Noop, ComponentDI).withCheck(checkRedis)
+
+	ErrRedisAddrsNotConfigured = errors.New("redis.addrs is required")
 )
 
 func WithRedis() Option {
@@ -19,6 +23,13 @@ func WithRedis() Option {
 	}
 }
 
+func checkRedis(app *Application) error {
+	if len(app.config.GetRedisConfig().Addrs) == 0 {
+		return ErrRedisAddrsNotConfigured
+	}
+	return nil
+}
+
 func initRedisClient(ctx context.Context, app *Application) error {
 	cfg := app.config.GetRedisConfig()
 
@@ -28,7 +39,7 @@ func initRedisClient(ctx context.Context, app *Application) error {
 		return err
 	}
 
-	app.Closer.Add(client.Close)
+	app.Closer.Add(ComponentRedis, closers.Wrap(client.Close), closers.WithPhase(closers.PhaseStorages))
 	app.Health.Add("redis", client.HealthCheck)
 	app.Redis = client
 
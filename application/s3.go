@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/config"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
@@ -11,7 +12,7 @@ import (
 )
 
 var (
-	s3Component = NewComponent("s3", initS3Client, runS3Client)
+	s3Component = NewComponent(ComponentS3, initS3Client, runS3Client, ComponentDI).withCheck(checkS3)
 )
 
 // WithS3 add S3 client component
@@ -22,6 +23,10 @@ func WithS3() Option {
 	}
 }
 
+func checkS3(app *Application) error {
+	return s3client.NewConfig(app.Env).Validate()
+}
+
 func initS3Client(ctx context.Context, app *Application) error {
 	logger.Info(ctx, "S3 initialize",
 		logger.String("component", "S3"),
@@ -41,24 +46,7 @@ func initS3Client(ctx context.Context, app *Application) error {
 		return err
 	}
 
-	app.Closer.Add(func() error {
-		var err error
-		defer func() {
-			if err != nil {
-				logger.Error(ctx, "Component S3 closed with error",
-					logger.Err(err),
-					logger.String("component", "S3"),
-				)
-				return
-			}
-			logger.Info(ctx, "Component closed",
-				logger.String("component", "S3"),
-			)
-		}()
-
-		err = client.Close()
-		return err
-	})
+	app.Closer.Add(ComponentS3, closers.Wrap(client.Close), closers.WithPhase(closers.PhaseStorages))
 
 	app.Health.Add("s3", func(ctx context.Context) error {
 		var err error
@@ -0,0 +1,294 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/lock"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"git.vepay.dev/knoknok/backend-platform/pkg/schedule"
+	"github.com/google/uuid"
+)
+
+const (
+	scheduleLockTTL       = 30 * time.Second // блокировка на время выполнения, продлевается каждые ttl/3
+	scheduleUnlockTimeout = 5 * time.Second
+	// scheduleLockHold удержание после запуска, меньше остатка ttl после последнего продления
+	scheduleLockHold = 10 * time.Second
+)
+
+// JobFunc функция задачи по расписанию, должна завершаться при отмене ctx
+type JobFunc func(ctx context.Context) error
+
+type ScheduleOption func(*job)
+
+// ScheduleJitter случайная задержка запуска в пределах [0, d),
+// чтобы реплики и разные задачи не стартовали одновременно
+func ScheduleJitter(d time.Duration) ScheduleOption {
+	return func(j *job) {
+		j.jitter = d
+	}
+}
+
+// ScheduleTimeout ограничивает время одного запуска
+func ScheduleTimeout(d time.Duration) ScheduleOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// ScheduleSingleReplica запуск только на одной реплике через блокировку в redis или postgres,
+// добавляет зависимость от соответствующего компонента
+func ScheduleSingleReplica(backend LockBackend) ScheduleOption {
+	return func(j *job) {
+		j.backend = backend
+		j.deps = append(j.deps, string(backend))
+	}
+}
+
+// ScheduleDeps компоненты, от которых зависит задача
+func ScheduleDeps(deps ...string) ScheduleOption {
+	return func(j *job) {
+		j.deps = append(j.deps, deps...)
+	}
+}
+
+type job struct {
+	name     string
+	fn       JobFunc
+	schedule schedule.Schedule
+	jitter   time.Duration
+	timeout  time.Duration
+	deps     []string
+
+	backend LockBackend
+	locker  lock.Locker
+	held    lock.Lock // блокировка текущего запуска, освобождается после scheduleLockHold
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WithSchedule добавляет задачу по расписанию, expr - cron из 5 полей, @hourly, @daily или @every <duration>.
+// Запуски одной задачи не пересекаются: пропущенные за время долгого запуска слоты не выполняются
+func WithSchedule(name, expr string, fn JobFunc, opts ...ScheduleOption) Option {
+	return func(app *Application) error {
+		s, err := schedule.Parse(expr)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", name, err)
+		}
+
+		j := newJob(name, s, fn, opts...)
+		run := func(ctx context.Context, app *Application) error {
+			return runJob(ctx, app, j)
+		}
+		if !app.components.add(component{name: name, initFn: Noop, runFn: run, deps: j.deps}) {
+			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, name)
+		}
+		return nil
+	}
+}
+
+func newJob(name string, s schedule.Schedule, fn JobFunc, opts ...ScheduleOption) *job {
+	j := &job{
+		name:     name,
+		fn:       fn,
+		schedule: s,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+func runJob(ctx context.Context, app *Application, j *job) error {
+	if j.backend != "" {
+		locker, err := j.backend.locker(app)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", j.name, err)
+		}
+		j.locker = locker
+	}
+
+	ctx, j.cancel = context.WithCancel(logger.With(ctx, logger.String("job", j.name)))
+	app.Closer.Add("schedule:"+j.name, j.Stop)
+
+	go j.loop(ctx)
+	return nil
+}
+
+// loop ждет следующего слота по расписанию и выполняет задачу
+func (j *job) loop(ctx context.Context) {
+	defer close(j.done)
+	defer j.release()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Warn(ctx, "Scheduled job will never run again")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next) + j.jitterDelay()):
+		}
+
+		j.tick(ctx, next)
+		if j.held != nil {
+			j.hold(ctx, j.schedule.Next(next))
+		}
+	}
+}
+
+func (j *job) jitterDelay() time.Duration {
+	if j.jitter <= 0 {
+		return 0
+	}
+	return rand.N(j.jitter)
+}
+
+// tick выполняет один запуск задачи для слота planned
+func (j *job) tick(ctx context.Context, planned time.Time) {
+	ctx = context.WithValue(ctx, logger.CorrelationId, uuid.NewString())
+
+	if j.locker != nil && !j.acquire(ctx) {
+		metrics.ScheduledJobRunsTotal.WithLabelValues(j.name, "skipped").Inc()
+		return
+	}
+
+	logger.Info(ctx, "Scheduled job started", logger.Time("planned", planned))
+	start := time.Now()
+	err := j.run(ctx)
+	duration := time.Since(start)
+
+	metrics.ScheduledJobDurationSeconds.WithLabelValues(j.name).Observe(duration.Seconds())
+	if err != nil {
+		metrics.ScheduledJobRunsTotal.WithLabelValues(j.name, "error").Inc()
+		logger.Error(ctx, "Scheduled job failed", logger.Duration("duration", duration), logger.Err(err))
+	} else {
+		metrics.ScheduledJobRunsTotal.WithLabelValues(j.name, "success").Inc()
+		metrics.ScheduledJobLastSuccess.WithLabelValues(j.name).SetToCurrentTime()
+		logger.Info(ctx, "Scheduled job finished", logger.Duration("duration", duration))
+	}
+
+	next := j.schedule.Next(planned)
+	if !next.IsZero() && next.Before(time.Now()) {
+		logger.Warn(ctx, "Scheduled job run exceeded its interval, missed runs skipped",
+			logger.Duration("duration", duration),
+		)
+	}
+}
+
+// hold недолго удерживает блокировку после запуска, чтобы реплика с отстающими часами не повторила слот,
+// затем освобождает ее: если реплика упадет, следующий слот выполнит другая
+func (j *job) hold(ctx context.Context, next time.Time) {
+	d := scheduleLockHold
+	if !next.IsZero() {
+		d = min(d, time.Until(next)/2)
+	}
+	if d > 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(d):
+		}
+	}
+	j.release()
+}
+
+// acquire захватывает блокировку задачи, предыдущая блокировка этой реплики освобождается
+func (j *job) acquire(ctx context.Context) bool {
+	j.release()
+
+	held, err := j.locker.TryLock(ctx, "schedule:"+j.name, scheduleLockTTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		logger.Debug(ctx, "Scheduled job skipped, running on another replica")
+		return false
+	}
+	if err != nil {
+		logger.Error(ctx, "Scheduled job skipped, lock failed", logger.Err(err))
+		return false
+	}
+	j.held = held
+	return true
+}
+
+func (j *job) release() {
+	if j.held == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), scheduleUnlockTimeout)
+	defer cancel()
+	if err := j.held.Unlock(ctx); err != nil {
+		logger.Warn(ctx, "Scheduled job unlock failed", logger.String("job", j.name), logger.Err(err))
+	}
+	j.held = nil
+}
+
+// run вызывает задачу, пока она выполняется блокировка продлевается,
+// при потере блокировки контекст задачи отменяется
+func (j *job) run(ctx context.Context) (err error) {
+	var cancel context.CancelFunc
+	if j.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	if j.held != nil {
+		refreshed := make(chan struct{})
+		go func() {
+			defer close(refreshed)
+			j.keepLock(ctx, cancel)
+		}()
+		defer func() { <-refreshed }()
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduled job panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+func (j *job) keepLock(ctx context.Context, cancel context.CancelFunc) {
+	t := time.NewTicker(scheduleLockTTL / 3)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := j.held.Refresh(ctx, scheduleLockTTL); err != nil && ctx.Err() == nil {
+				logger.Error(ctx, "Scheduled job lock lost, cancelling run", logger.Err(err))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// Stop отменяет ожидание и текущий запуск задачи и ждет завершения
+func (j *job) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/lock"
+	"git.vepay.dev/knoknok/backend-platform/pkg/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLocker блокировки в памяти, общие для нескольких "реплик"
+type memLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+type memLock struct {
+	l   *memLocker
+	key string
+}
+
+func (m *memLocker) TryLock(_ context.Context, key string, _ time.Duration) (lock.Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.held[key] {
+		return nil, fmt.Errorf("%w: %s", lock.ErrNotAcquired, key)
+	}
+	m.held[key] = true
+	return &memLock{l: m, key: key}, nil
+}
+
+func (m *memLock) Refresh(context.Context, time.Duration) error { return nil }
+
+func (m *memLock) Unlock(context.Context) error {
+	m.l.mu.Lock()
+	defer m.l.mu.Unlock()
+	delete(m.l.held, m.key)
+	return nil
+}
+
+func TestJobRunsOnSchedule(t *testing.T) {
+	var calls atomic.Int32
+	j := newJob("test", schedule.Every(10*time.Millisecond), func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	go j.loop(ctx)
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, j.Stop(context.Background()))
+}
+
+func TestJobSingleReplica(t *testing.T) {
+	locker := &memLocker{held: map[string]bool{}}
+	var calls atomic.Int32
+	fn := func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+
+	first := newJob("test", schedule.Every(time.Hour), fn)
+	first.locker = locker
+	second := newJob("test", schedule.Every(time.Hour), fn)
+	second.locker = locker
+
+	slot := time.Now()
+	first.tick(context.Background(), slot)
+	// первая реплика держит блокировку после запуска
+	second.tick(context.Background(), slot)
+	assert.Equal(t, int32(1), calls.Load())
+
+	first.release()
+	second.tick(context.Background(), slot.Add(time.Hour))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestJobLockReleasedAfterHold(t *testing.T) {
+	locker := &memLocker{held: map[string]bool{}}
+	j := newJob("test", schedule.Every(time.Hour), func(context.Context) error { return nil })
+	j.locker = locker
+
+	j.tick(context.Background(), time.Now())
+	assert.True(t, locker.held["schedule:test"])
+
+	// удержание не дольше половины времени до следующего слота
+	start := time.Now()
+	j.hold(context.Background(), time.Now().Add(40*time.Millisecond))
+	assert.Less(t, time.Since(start), scheduleLockHold)
+	assert.False(t, locker.held["schedule:test"])
+}
+
+func TestJobPanicRecovered(t *testing.T) {
+	j := newJob("test", schedule.Every(time.Hour), func(context.Context) error {
+		panic("boom")
+	})
+	assert.ErrorContains(t, j.run(context.Background()), "boom")
+}
+
+func TestWithScheduleInvalidExpr(t *testing.T) {
+	app := &Application{components: newComponents()}
+	err := WithSchedule("test", "* * *", func(context.Context) error { return nil })(app)
+	assert.ErrorIs(t, err, schedule.ErrInvalidSchedule)
+}
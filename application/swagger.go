@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"git.vepay.dev/knoknok/backend-platform/pkg/swagger"
+	"net/http"
 )
 
 var (
-	swaggerComponent = NewComponent("swagger", initSwagger, Noop)
+	swaggerComponent = NewComponent(ComponentSwagger, initSwagger, Noop)
 )
 
 // WithSwagger — включаем компонент
@@ -59,6 +60,18 @@ func initSwagger(ctx context.Context, app *Application) error {
 	if err != nil {
 		return fmt.Errorf("swagger init failed: %w", err)
 	}
-	app.middlewares.Add(mw)
+	app.swaggerUI = mw
 	return nil
 }
+
+// swaggerMiddleware ui и gateway создаются в init компонента, мидлварь добавляется раньше,
+// последней из мидлварей компонентов: gateway обслуживает API и выполняется после auth
+func (a *Application) swaggerMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.swaggerUI == nil {
+			next(w, r)
+			return
+		}
+		a.swaggerUI(next)(w, r)
+	}
+}
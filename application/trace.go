@@ -3,12 +3,13 @@ package application
 import (
 	"context"
 
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 	"git.vepay.dev/knoknok/backend-platform/pkg/trace"
 )
 
 var (
-	traceComponent = NewComponent("trace", initTrace, Noop)
+	traceComponent = NewComponent(ComponentTrace, initTrace, Noop).withCheck(checkTrace)
 )
 
 // WithTrace добавляет OpenTelemetry трассировку
@@ -19,6 +20,11 @@ func WithTrace() Option {
 	}
 }
 
+func checkTrace(app *Application) error {
+	_, err := trace.GetTracingConfig(app.Env)
+	return err
+}
+
 // initTrace инициализация OpenTelemetry
 func initTrace(ctx context.Context, app *Application) error {
 
@@ -38,9 +44,7 @@ func initTrace(ctx context.Context, app *Application) error {
 		return err
 	}
 
-	app.Closer.Add(func() error {
-		return shutdown(ctx)
-	})
+	app.Closer.Add(ComponentTrace, shutdown, closers.WithPhase(closers.PhaseTelemetry))
 
 	logger.Info(ctx, "Tracing initialized successfully")
 	return nil
@@ -0,0 +1,239 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/health"
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+const (
+	defaultWorkerMinBackoff = time.Second
+	defaultWorkerMaxBackoff = time.Minute
+)
+
+var (
+	ErrWorkerFailed = errors.New("worker failed")
+)
+
+// WorkerFunc функция фонового воркера, должна завершаться при отмене ctx
+type WorkerFunc func(ctx context.Context) error
+
+// WorkerState состояние воркера
+type WorkerState string
+
+const (
+	WorkerStarting WorkerState = "starting"
+	WorkerRunning  WorkerState = "running"
+	WorkerBackoff  WorkerState = "backoff" // ожидание перезапуска после ошибки
+	WorkerStopped  WorkerState = "stopped" // воркер завершился без ошибки или остановлен
+	WorkerFailed   WorkerState = "failed"  // воркер упал и больше не перезапускается
+)
+
+// RestartPolicy политика перезапуска воркера
+type RestartPolicy struct {
+	Restart     bool          // перезапускать при ошибке
+	MaxRestarts int           // максимальное количество перезапусков подряд, 0 - без ограничений
+	MinBackoff  time.Duration // пауза перед первым перезапуском, по умолчанию 1s
+	MaxBackoff  time.Duration // максимальная пауза, по умолчанию 1m
+	Critical    bool          // при окончательном падении воркера приложение останавливается с ошибкой
+}
+
+// NoRestart воркер не перезапускается
+var NoRestart = RestartPolicy{}
+
+// RestartOnError перезапуск при ошибке с экспоненциальной паузой
+func RestartOnError(maxRestarts int) RestartPolicy {
+	return RestartPolicy{
+		Restart:     true,
+		MaxRestarts: maxRestarts,
+	}
+}
+
+type worker struct {
+	name   string
+	fn     WorkerFunc
+	policy RestartPolicy
+
+	mu       sync.RWMutex
+	state    WorkerState
+	restarts int
+	lastErr  error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WithWorker добавляет фоновый воркер, привязанный к жизненному циклу приложения:
+// воркер стартует на этапе run, состояние доступно в Health,
+// при остановке контекст воркера отменяется и остановка ждет его завершения
+func WithWorker(name string, fn WorkerFunc, policy RestartPolicy, deps ...string) Option {
+	return func(app *Application) error {
+		w := newWorker(name, fn, policy)
+		run := func(ctx context.Context, app *Application) error {
+			return runWorker(ctx, app, w)
+		}
+		if !app.components.add(component{name: name, initFn: Noop, runFn: run, deps: deps}) {
+			return fmt.Errorf("%w: %s", ErrComponentAlreadyExist, name)
+		}
+		return nil
+	}
+}
+
+func newWorker(name string, fn WorkerFunc, policy RestartPolicy) *worker {
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = defaultWorkerMinBackoff
+	}
+	if policy.MaxBackoff < policy.MinBackoff {
+		policy.MaxBackoff = max(defaultWorkerMaxBackoff, policy.MinBackoff)
+	}
+	return &worker{
+		name:   name,
+		fn:     fn,
+		policy: policy,
+		state:  WorkerStarting,
+		done:   make(chan struct{}),
+	}
+}
+
+func runWorker(ctx context.Context, app *Application, w *worker) error {
+	ctx, w.cancel = context.WithCancel(logger.With(ctx, logger.String("worker", w.name)))
+
+	// упавший некритичный воркер не снимает под с балансировки
+	var opts []health.CheckOption
+	if !w.policy.Critical {
+		opts = append(opts, health.Optional())
+	}
+	app.Health.Add("worker:"+w.name, w.HealthCheck, opts...)
+	app.Closer.Add("worker:"+w.name, w.Stop)
+
+	go func() {
+		if err := w.loop(ctx); err != nil && w.policy.Critical {
+			app.Fail(w.name, err)
+		}
+	}()
+	return nil
+}
+
+// loop запускает воркер и перезапускает его согласно политике,
+// возвращает ошибку если воркер окончательно упал
+func (w *worker) loop(ctx context.Context) error {
+	defer close(w.done)
+
+	attempt := 0
+	for {
+		w.setState(WorkerRunning, nil)
+		logger.Info(ctx, "Worker started", logger.Int("restarts", w.Restarts()))
+
+		start := time.Now()
+		err := w.call(ctx)
+
+		if ctx.Err() != nil {
+			w.setState(WorkerStopped, err)
+			logger.Info(ctx, "Worker stopped")
+			return nil
+		}
+		if err == nil {
+			w.setState(WorkerStopped, nil)
+			logger.Info(ctx, "Worker finished")
+			return nil
+		}
+
+		// долго проработавший воркер начинает отсчет перезапусков заново
+		if time.Since(start) > w.policy.MaxBackoff {
+			attempt = 0
+		}
+
+		if !w.policy.Restart || (w.policy.MaxRestarts > 0 && attempt >= w.policy.MaxRestarts) {
+			w.setState(WorkerFailed, err)
+			logger.Error(ctx, "Worker failed", logger.Err(err))
+			return fmt.Errorf("%w: %s: %w", ErrWorkerFailed, w.name, err)
+		}
+
+		backoff := w.backoff(attempt)
+		attempt++
+		w.setState(WorkerBackoff, err)
+		w.mu.Lock()
+		w.restarts++
+		w.mu.Unlock()
+		logger.Error(ctx, "Worker failed, restarting",
+			logger.Err(err),
+			logger.Int("attempt", attempt),
+			logger.Duration("backoff", backoff),
+		)
+
+		select {
+		case <-ctx.Done():
+			w.setState(WorkerStopped, err)
+			logger.Info(ctx, "Worker stopped")
+			return nil
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// call вызывает функцию воркера, паника превращается в ошибку
+func (w *worker) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("worker panic: %v", r)
+		}
+	}()
+	return w.fn(ctx)
+}
+
+func (w *worker) backoff(attempt int) time.Duration {
+	backoff := w.policy.MinBackoff
+	for i := 0; i < attempt && backoff < w.policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, w.policy.MaxBackoff)
+}
+
+func (w *worker) setState(state WorkerState, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = state
+	w.lastErr = err
+}
+
+func (w *worker) State() WorkerState {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state
+}
+
+func (w *worker) Restarts() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.restarts
+}
+
+// HealthCheck возвращает ошибку если воркер окончательно упал
+func (w *worker) HealthCheck(context.Context) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.state == WorkerFailed {
+		return fmt.Errorf("%w: %s, state %s: %w", ErrWorkerFailed, w.name, w.state, w.lastErr)
+	}
+	return nil
+}
+
+// Stop отменяет контекст воркера и ждет его завершения
+func (w *worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
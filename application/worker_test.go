@@ -0,0 +1,69 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerRestartLimit(t *testing.T) {
+	errWork := errors.New("work failed")
+	var calls atomic.Int32
+
+	w := newWorker("test", func(ctx context.Context) error {
+		calls.Add(1)
+		return errWork
+	}, RestartPolicy{Restart: true, MaxRestarts: 2, MinBackoff: time.Millisecond})
+
+	err := w.loop(context.Background())
+	assert.ErrorIs(t, err, ErrWorkerFailed)
+	assert.ErrorIs(t, err, errWork)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 2, w.Restarts())
+	assert.Equal(t, WorkerFailed, w.State())
+	assert.ErrorIs(t, w.HealthCheck(context.Background()), errWork)
+}
+
+func TestWorkerPanicNoRestart(t *testing.T) {
+	w := newWorker("test", func(ctx context.Context) error {
+		panic("boom")
+	}, NoRestart)
+
+	err := w.loop(context.Background())
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, WorkerFailed, w.State())
+}
+
+func TestWorkerStop(t *testing.T) {
+	started := make(chan struct{})
+	w := newWorker("test", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, RestartOnError(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	go w.loop(ctx)
+	<-started
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Second)
+	defer stopCancel()
+	require.NoError(t, w.Stop(stopCtx))
+	assert.Equal(t, WorkerStopped, w.State())
+	assert.NoError(t, w.HealthCheck(context.Background()))
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	w := newWorker("test", nil, RestartPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, w.backoff(0))
+	assert.Equal(t, 2*time.Second, w.backoff(1))
+	assert.Equal(t, 4*time.Second, w.backoff(2))
+	assert.Equal(t, 5*time.Second, w.backoff(3))
+}
@@ -3,12 +3,15 @@ package application
 import (
 	"context"
 	"errors"
+	"git.vepay.dev/knoknok/backend-platform/internal/pkg/closers"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"git.vepay.dev/knoknok/backend-platform/pkg/workflow"
 )
 
 var (
-	workflowComponent = NewComponent("workflow", initWorkflow, runWorkflow)
+	workflowComponent = NewComponent(ComponentWorkflow, initWorkflow, runWorkflow, ComponentDI).withCheck(checkWorkflow)
+
+	ErrWorkflowHostNotConfigured = errors.New("workflow.host is required")
 )
 
 func WithWorkflow() Option {
@@ -18,6 +21,13 @@ func WithWorkflow() Option {
 	}
 }
 
+func checkWorkflow(app *Application) error {
+	if app.config.getWorkflowConfig().Host == "" {
+		return ErrWorkflowHostNotConfigured
+	}
+	return nil
+}
+
 func initWorkflow(_ context.Context, app *Application) error {
 	cfg := app.config.getWorkflowConfig()
 	app.Workflow = workflow.NewWorkflowBuilder(
@@ -36,9 +46,8 @@ func runWorkflow(ctx context.Context, app *Application) error {
 
 	app.workflow = app.Workflow.Run(ctx)
 	app.Health.Add("workflow", app.workflow.Health)
-	app.Closer.Add(func() error {
-		return app.workflow.Close(ctx)
-	})
+	app.Readiness.Add("workflow", app.workflow.Ready)
+	app.Closer.Add(ComponentWorkflow, app.workflow.Close, closers.WithPhase(closers.PhaseServers))
 
 	di.Register(ctx, app.workflow)
 	return nil
@@ -2,55 +2,243 @@
 package closers
 
 import (
-	"container/list"
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"sort"
 	"sync"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+var (
+	ErrCloserTimeout = errors.New("closer timeout")
+	ErrCloserSkipped = errors.New("closer skipped")
+)
+
+type CloserFunc func(context.Context) error
+
+// Phase фаза остановки, фазы закрываются по возрастанию,
+// внутри фазы closers выполняются в обратном порядке добавления (LIFO)
+type Phase int
+
+const (
+	PhaseServers   Phase = iota * 10 // остановка приема трафика: http, grpc, consumers
+	PhaseDefault                     // пользовательские компоненты
+	PhaseStorages                    // хранилища: postgres, redis, s3
+	PhaseTelemetry                   // трассировка, метрики, конфиг
 )
 
-type CloserFunc func() error
+func (p Phase) String() string {
+	switch p {
+	case PhaseServers:
+		return "servers"
+	case PhaseDefault:
+		return "default"
+	case PhaseStorages:
+		return "storages"
+	case PhaseTelemetry:
+		return "telemetry"
+	}
+	return fmt.Sprintf("phase_%d", int(p))
+}
+
+type Option func(*item)
+
+// WithTimeout ограничивает время выполнения closer,
+// по умолчанию ограничен только общим контекстом Close
+func WithTimeout(timeout time.Duration) Option {
+	return func(i *item) {
+		i.timeout = timeout
+	}
+}
+
+// WithPhase задает фазу остановки, по умолчанию PhaseDefault
+func WithPhase(phase Phase) Option {
+	return func(i *item) {
+		i.phase = phase
+	}
+}
+
+// Wrap адаптер для функций закрытия без контекста
+func Wrap(fn func() error) CloserFunc {
+	return func(context.Context) error {
+		return fn()
+	}
+}
+
+type item struct {
+	name    string
+	fn      CloserFunc
+	phase   Phase
+	timeout time.Duration
+}
 
 type closer struct {
-	list list.List
-	m    sync.Mutex
+	list     []item
+	timeouts map[Phase]time.Duration
+	m        sync.Mutex
+}
+
+// Info описание зарегистрированного closer в порядке выполнения
+type Info struct {
+	Name    string        `json:"name"`
+	Phase   string        `json:"phase"`
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
 type Closer interface {
-	Add(item CloserFunc)
+	Add(name string, fn CloserFunc, opts ...Option)
+	// List возвращает closers в порядке выполнения при Close
+	List() []Info
+	// SetPhaseTimeout ограничивает время выполнения всех closers фазы
+	SetPhaseTimeout(phase Phase, timeout time.Duration)
 	Close(context.Context) error
 }
 
 func New() Closer {
-	return &closer{}
+	return &closer{
+		timeouts: make(map[Phase]time.Duration),
+	}
 }
 
-func (c *closer) Add(item CloserFunc) {
+func (c *closer) Add(name string, fn CloserFunc, opts ...Option) {
+	i := item{name: name, fn: fn, phase: PhaseDefault}
+	for _, opt := range opts {
+		opt(&i)
+	}
+
 	c.m.Lock()
 	defer c.m.Unlock()
-	c.list.PushFront(item)
+	c.list = append(c.list, i)
 }
 
-func (c *closer) Close(ctx context.Context) error {
+func (c *closer) List() []Info {
 	c.m.Lock()
 	defer c.m.Unlock()
-	res := make(chan error, 1)
-	closeAll := func() {
-		var err error
-		for e := c.list.Front(); e != nil; e = e.Next() {
-			closer := e.Value.(CloserFunc)
-			closeErr := closer()
-			err = errors.Join(err, closeErr)
+
+	items := c.ordered()
+	res := make([]Info, 0, len(items))
+	for _, i := range items {
+		res = append(res, Info{Name: i.name, Phase: i.phase.String(), Timeout: i.timeout})
+	}
+	return res
+}
+
+func (c *closer) SetPhaseTimeout(phase Phase, timeout time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.timeouts[phase] = timeout
+}
+
+// Close выполняет closers по фазам,
+// при истечении ctx или таймаута фазы оставшиеся closers пропускаются
+func (c *closer) Close(ctx context.Context) error {
+	// closers выполняются без блокировки, чтобы List отвечал во время остановки
+	c.m.Lock()
+	items := c.ordered()
+	timeouts := maps.Clone(c.timeouts)
+	c.m.Unlock()
+
+	var err error
+	for start := 0; start < len(items); {
+		if ctx.Err() != nil {
+			logger.Error(ctx, "Closers skipped, shutdown deadline exceeded", logger.Int("skipped", len(items)-start))
+			return errors.Join(err, skip(items[start:]))
+		}
+
+		end := start
+		for end < len(items) && items[end].phase == items[start].phase {
+			end++
+		}
+
+		err = errors.Join(err, c.closePhase(ctx, items[start].phase, timeouts[items[start].phase], items[start:end]))
+		start = end
+	}
+	return err
+}
+
+func (c *closer) closePhase(ctx context.Context, phase Phase, timeout time.Duration, items []item) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	logger.Info(ctx, "Shutdown phase started", logger.String("phase", phase.String()))
+
+	var err error
+	for n, i := range items {
+		if ctx.Err() != nil {
+			logger.Error(ctx, "Closers skipped, shutdown phase deadline exceeded",
+				logger.String("phase", phase.String()),
+				logger.Int("skipped", len(items)-n),
+			)
+			return errors.Join(err, skip(items[n:]))
 		}
-		res <- err
-		close(res)
+		err = errors.Join(err, c.closeItem(ctx, i))
+	}
+
+	logger.Info(ctx, "Shutdown phase finished",
+		logger.String("phase", phase.String()),
+		logger.Duration("duration", time.Since(start)),
+	)
+	return err
+}
+
+func skip(items []item) error {
+	var err error
+	for _, i := range items {
+		err = errors.Join(err, fmt.Errorf("%w: %s", ErrCloserSkipped, i.name))
+	}
+	return err
+}
+
+// ordered возвращает closers по фазам, внутри фазы в порядке LIFO
+func (c *closer) ordered() []item {
+	items := make([]item, 0, len(c.list))
+	for n := len(c.list) - 1; n >= 0; n-- {
+		items = append(items, c.list[n])
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].phase < items[b].phase
+	})
+	return items
+}
+
+func (c *closer) closeItem(ctx context.Context, i item) error {
+	if i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
 	}
 
-	go closeAll()
+	start := time.Now()
+	res := make(chan error, 1)
+	go func() {
+		res <- i.fn(ctx)
+	}()
 
+	var err error
 	select {
+	case err = <-res:
 	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-res:
-		return err
+		err = fmt.Errorf("%w after %s", ErrCloserTimeout, time.Since(start))
 	}
+
+	fields := []logger.Field{
+		logger.String("closer", i.name),
+		logger.String("phase", i.phase.String()),
+		logger.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		logger.Error(ctx, "Closer failed", append(fields, logger.Err(err))...)
+		return fmt.Errorf("closer %s: %w", i.name, err)
+	}
+
+	logger.Info(ctx, "Closer finished", fields...)
+	return nil
 }
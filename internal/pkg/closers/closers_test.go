@@ -0,0 +1,126 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseOrder(t *testing.T) {
+	var order []string
+	add := func(c Closer, name string, opts ...Option) {
+		c.Add(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}, opts...)
+	}
+
+	c := New()
+	add(c, "postgres", WithPhase(PhaseStorages))
+	add(c, "http", WithPhase(PhaseServers))
+	add(c, "worker")
+	add(c, "redis", WithPhase(PhaseStorages))
+	add(c, "trace", WithPhase(PhaseTelemetry))
+
+	assert.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, []string{"http", "worker", "redis", "postgres", "trace"}, order)
+}
+
+func TestCloseTimeout(t *testing.T) {
+	errClose := errors.New("close failed")
+	var deadline atomic.Bool
+
+	c := New()
+	c.Add("hung", func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		deadline.Store(ok)
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeout(50*time.Millisecond))
+	c.Add("failed", func(context.Context) error {
+		return errClose
+	})
+
+	err := c.Close(context.Background())
+	assert.ErrorIs(t, err, ErrCloserTimeout)
+	assert.ErrorContains(t, err, "closer hung")
+	assert.ErrorIs(t, err, errClose)
+	assert.True(t, deadline.Load())
+}
+
+func TestCloseSkippedAfterDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := New()
+	c.Add("never", func(context.Context) error {
+		return nil
+	})
+	c.Add("hung", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	err := c.Close(ctx)
+	assert.ErrorIs(t, err, ErrCloserTimeout)
+	assert.ErrorIs(t, err, ErrCloserSkipped)
+	assert.ErrorContains(t, err, "never")
+}
+
+func TestClosePhaseTimeout(t *testing.T) {
+	var closed atomic.Bool
+
+	c := New()
+	c.SetPhaseTimeout(PhaseServers, 50*time.Millisecond)
+	c.Add("storage", func(context.Context) error {
+		closed.Store(true)
+		return nil
+	}, WithPhase(PhaseStorages))
+	c.Add("skipped", func(context.Context) error {
+		return nil
+	}, WithPhase(PhaseServers))
+	c.Add("hung", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	}, WithPhase(PhaseServers))
+
+	err := c.Close(context.Background())
+	assert.ErrorIs(t, err, ErrCloserTimeout)
+	assert.ErrorIs(t, err, ErrCloserSkipped)
+	assert.ErrorContains(t, err, "skipped")
+	assert.True(t, closed.Load())
+}
+
+func TestListDuringClose(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	c := New()
+	c.Add("http", func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}, WithPhase(PhaseServers))
+
+	closed := make(chan error, 1)
+	go func() { closed <- c.Close(context.Background()) }()
+	<-started
+
+	listed := make(chan []Info, 1)
+	go func() { listed <- c.List() }()
+	select {
+	case list := <-listed:
+		assert.Equal(t, []Info{{Name: "http", Phase: "servers"}}, list)
+	case <-time.After(time.Second):
+		t.Fatal("List blocked by Close")
+	}
+
+	close(release)
+	assert.NoError(t, <-closed)
+}
@@ -84,6 +84,17 @@ func (c *Config) LoadEnv(ctx context.Context) error {
 	return nil
 }
 
+// LoadEnvMap load config from values instead of file, used in tests
+func (c *Config) LoadEnvMap(values map[string]any) error {
+	viperInst := viper.New()
+	if err := viperInst.MergeConfigMap(values); err != nil {
+		return fmt.Errorf("failed to load config from map %w", err)
+	}
+
+	c.envViper = viperInst
+	return nil
+}
+
 // Bootstrap config file to provider.
 // Load config file envs and save to config provider
 func (c *Config) Bootstrap(ctx context.Context, provider cp.Provider) error {
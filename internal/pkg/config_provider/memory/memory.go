@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	configprovider "git.vepay.dev/knoknok/backend-platform/internal/pkg/config_provider"
+)
+
+// memoryProvider хранит конфиг в памяти, используется в тестах вместо consul/vault.
+// Set уведомляет подписчиков Watch так же, как изменение ключей в consul
+type memoryProvider struct {
+	mu       sync.RWMutex
+	data     configprovider.ConfigData
+	onChange []func(map[string]interface{})
+}
+
+func NewProvider(data configprovider.ConfigData) configprovider.Provider {
+	return &memoryProvider{
+		data: maps.Clone(data),
+	}
+}
+
+func (m *memoryProvider) Get(ctx context.Context) (configprovider.ConfigData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.data), nil
+}
+
+func (m *memoryProvider) Set(ctx context.Context, value configprovider.ConfigData) error {
+	m.mu.Lock()
+	if m.data == nil {
+		m.data = make(configprovider.ConfigData)
+	}
+	maps.Copy(m.data, value)
+	handlers := m.onChange
+	m.mu.Unlock()
+
+	for _, onChange := range handlers {
+		onChange(value)
+	}
+	return nil
+}
+
+func (m *memoryProvider) Watch(ctx context.Context, onChange func(map[string]interface{})) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = append(m.onChange, onChange)
+	return nil
+}
+
+func (m *memoryProvider) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = nil
+	return nil
+}
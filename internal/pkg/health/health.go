@@ -3,63 +3,369 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
-	"golang.org/x/sync/errgroup"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
 )
 
+const defaultProbe = "health"
+
 type CheckFunc func(context.Context) error
 
 type Check struct {
 	Name string
 	Func CheckFunc
+	// Critical ошибка проверки делает приложение не готовым, иначе только degraded
+	Critical bool
+	// Interval период фонового опроса после Start, 0 - проверка выполняется при каждом запросе
+	Interval time.Duration
+	// Timeout таймаут одного выполнения проверки, 0 - без собственного таймаута
+	Timeout time.Duration
+	// FailureThreshold ошибок подряд, после которых проверка считается упавшей
+	FailureThreshold int
+	// SuccessThreshold успехов подряд, после которых упавшая проверка считается восстановленной
+	SuccessThreshold int
+}
+
+// CheckOption настройка проверки при добавлении
+type CheckOption func(*Check)
+
+// Optional упавшая проверка не снимает под с балансировки, отчет получает статус degraded
+func Optional() CheckOption {
+	return func(c *Check) {
+		c.Critical = false
+	}
+}
+
+// Interval проверка опрашивается в фоне, запросы получают последний результат
+func Interval(d time.Duration) CheckOption {
+	return func(c *Check) {
+		c.Interval = d
+	}
+}
+
+// Timeout таймаут одного выполнения проверки
+func Timeout(d time.Duration) CheckOption {
+	return func(c *Check) {
+		c.Timeout = d
+	}
+}
+
+// Thresholds состояние проверки меняется после failure ошибок или success успехов подряд.
+// Первый результат задает состояние сразу
+func Thresholds(failure, success int) CheckOption {
+	return func(c *Check) {
+		c.FailureThreshold = failure
+		c.SuccessThreshold = success
+	}
+}
+
+// Option настройка Health
+type Option func(*health)
+
+// WithProbe название набора проверок в метке probe метрик, по умолчанию health
+func WithProbe(probe string) Option {
+	return func(h *health) {
+		h.probe = probe
+	}
+}
+
+// WithDefaults настройки всех проверок, опции Add их переопределяют
+func WithDefaults(opts ...CheckOption) Option {
+	return func(h *health) {
+		h.defaults = append(h.defaults, opts...)
+	}
 }
 
+// WithOverrides настройки проверки по названию, например из конфига, переопределяют опции Add
+func WithOverrides(fn func(name string) []CheckOption) Option {
+	return func(h *health) {
+		h.overrides = fn
+	}
+}
+
+// Status состояние проверки или приложения в отчете
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded" // упали только необязательные проверки
+	StatusDown     Status = "down"
+)
+
 type health struct {
-	checks []Check
+	probe     string
+	defaults  []CheckOption
+	overrides func(name string) []CheckOption
+
+	checks []*check
 	m      sync.RWMutex
+	// ctx фонового опроса, задается в Start
+	ctx context.Context
 }
 
 type Health interface {
-	Add(name string, fn CheckFunc)
+	// Add добавляет проверку, по умолчанию критичную
+	Add(name string, fn CheckFunc, opts ...CheckOption)
+	// Check выполняет все проверки и возвращает ошибки критичных
 	Check(ctx context.Context) error
+	// Results выполняет все проверки и возвращает результат каждой
+	Results(ctx context.Context) []Result
+	// Report выполняет все проверки и возвращает общий статус
+	Report(ctx context.Context) Report
+	// Start запускает фоновый опрос проверок с Interval до отмены ctx,
+	// проверки, добавленные позже, опрашиваются сразу после добавления
+	Start(ctx context.Context)
 }
 
-func New() Health {
-	return &health{}
+// Result результат одной проверки
+type Result struct {
+	Name        string        `json:"name"`
+	Status      Status        `json:"status"`
+	Critical    bool          `json:"critical"`
+	Error       string        `json:"error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	LastSuccess time.Time     `json:"last_success,omitzero"`
+	CheckedAt   time.Time     `json:"checked_at,omitzero"`
+	// Failures ошибок подряд, до FailureThreshold состояние остается up
+	Failures int `json:"failures,omitempty"`
 }
 
-func (c *health) Add(name string, fn CheckFunc) {
-	c.m.Lock()
-	defer c.m.Unlock()
-	c.checks = append(c.checks, Check{Name: name, Func: fn})
+// Report результаты всех проверок
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
 }
 
-// Check run async check all registered components
-func (c *health) Check(ctx context.Context) error {
-	c.m.RLock()
-	defer c.m.RUnlock()
+// Failed названия упавших проверок, только критичных или всех
+func (r Report) Failed(critical bool) []string {
+	var names []string
+	for _, res := range r.Checks {
+		if res.Status == StatusDown && (res.Critical || !critical) {
+			names = append(names, res.Name)
+		}
+	}
+	return names
+}
 
-	if len(c.checks) == 0 {
-		return nil
+func New(opts ...Option) Health {
+	h := &health{probe: defaultProbe}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
+}
+
+func (h *health) Add(name string, fn CheckFunc, opts ...CheckOption) {
+	c := &check{Check: Check{Name: name, Func: fn, Critical: true}}
+	for _, opt := range h.defaults {
+		opt(&c.Check)
+	}
+	for _, opt := range opts {
+		opt(&c.Check)
+	}
+	if h.overrides != nil {
+		for _, opt := range h.overrides(name) {
+			opt(&c.Check)
+		}
+	}
+	c.FailureThreshold = max(c.FailureThreshold, 1)
+	c.SuccessThreshold = max(c.SuccessThreshold, 1)
 
-	g, ctx := errgroup.WithContext(ctx)
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.checks = append(h.checks, c)
+	if h.ctx != nil && c.Interval > 0 {
+		go h.poll(h.ctx, c)
+	}
+}
 
-	for _, check := range c.checks {
-		g.Go(func() error {
-			if err := check.Func(ctx); err != nil {
-				return &CheckError{
-					Name: check.Name,
-					Err:  err,
-				}
-			}
-			return nil
-		})
+func (h *health) Start(ctx context.Context) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.ctx != nil {
+		return
+	}
+	h.ctx = ctx
+	for _, c := range h.checks {
+		if c.Interval > 0 {
+			go h.poll(ctx, c)
+		}
 	}
+}
 
-	return g.Wait()
+// Check run all checks concurrently, returns CheckError of every critical check in down state
+func (h *health) Check(ctx context.Context) error {
+	var errs []error
+	for _, c := range h.run(ctx) {
+		if err := c.failure(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Results run all checks concurrently, unlike Check doesn't stop on first error
+func (h *health) Results(ctx context.Context) []Result {
+	checks := h.run(ctx)
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		results = append(results, c.result())
+	}
+	return results
+}
+
+// Report run all checks concurrently, status is down if any critical check failed
+func (h *health) Report(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: h.Results(ctx)}
+	for _, res := range report.Checks {
+		if res.Status != StatusDown {
+			continue
+		}
+		if res.Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// run выполняет проверки без фонового опроса, опрашиваемые в фоне выполняются только до первого результата
+func (h *health) run(ctx context.Context) []*check {
+	h.m.RLock()
+	checks := h.checks
+	polling := h.ctx != nil
+	h.m.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		if polling && c.Interval > 0 && c.checked() {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.execute(ctx, c, false)
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+func (h *health) poll(ctx context.Context, c *check) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		h.execute(ctx, c, true)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *health) execute(ctx context.Context, c *check, polling bool) {
+	parent := ctx
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := c.Func(ctx)
+	// остановка опроса не меняет состояние, истекший таймаут запроса считается ошибкой
+	if polling && parent.Err() != nil {
+		return
+	}
+	c.record(h.probe, err, time.Since(start), start)
+}
+
+// check проверка и ее состояние с учетом порогов
+type check struct {
+	Check
+
+	mu          sync.Mutex
+	state       Status // пустое до первого результата
+	failures    int
+	successes   int
+	err         error
+	duration    time.Duration
+	checkedAt   time.Time
+	lastSuccess time.Time
+}
+
+func (c *check) record(probe string, err error, duration time.Duration, at time.Time) {
+	metrics.HealthCheckDuration.WithLabelValues(probe, c.Name).Observe(duration.Seconds())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.duration, c.checkedAt = duration, at
+	if err != nil {
+		c.failures, c.successes, c.err = c.failures+1, 0, err
+	} else {
+		c.failures, c.successes, c.lastSuccess = 0, c.successes+1, at
+	}
+
+	state := c.state
+	switch {
+	case state == "" && err != nil, state == StatusUp && c.failures >= c.FailureThreshold:
+		state = StatusDown
+	case state == "", state == StatusDown && c.successes >= c.SuccessThreshold:
+		state = StatusUp
+	}
+	if state == StatusUp && err == nil {
+		c.err = nil
+	}
+	if state == c.state {
+		return
+	}
+	c.state = state
+
+	up := 0.0
+	if state == StatusUp {
+		up = 1
+	}
+	metrics.HealthCheckUp.WithLabelValues(probe, c.Name).Set(up)
+	metrics.HealthCheckTransitionsTotal.WithLabelValues(probe, c.Name, string(state)).Inc()
+}
+
+func (c *check) checked() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state != ""
+}
+
+// failure ошибка критичной упавшей проверки
+func (c *check) failure() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.Critical || c.state != StatusDown {
+		return nil
+	}
+	return &CheckError{Name: c.Name, Err: c.err}
+}
+
+func (c *check) result() Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := Result{
+		Name:        c.Name,
+		Status:      c.state,
+		Critical:    c.Critical,
+		Duration:    c.duration,
+		LastSuccess: c.lastSuccess,
+		CheckedAt:   c.checkedAt,
+		Failures:    c.failures,
+	}
+	if c.err != nil {
+		res.Error = c.err.Error()
+	}
+	return res
 }
 
 type CheckError struct {
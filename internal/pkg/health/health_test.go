@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	var cacheErr, dbErr error
+	h := New()
+	h.Add("db", func(context.Context) error { return dbErr })
+	h.Add("cache", func(context.Context) error { return cacheErr }, Optional())
+
+	report := h.Report(ctx)
+	assert.Equal(t, StatusUp, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.True(t, report.Checks[0].Critical)
+	assert.False(t, report.Checks[1].Critical)
+	lastSuccess := report.Checks[1].LastSuccess
+	assert.False(t, lastSuccess.IsZero())
+
+	// упала только необязательная проверка
+	cacheErr = errors.New("cache unavailable")
+	report = h.Report(ctx)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, StatusDown, report.Checks[1].Status)
+	assert.Equal(t, "cache unavailable", report.Checks[1].Error)
+	assert.Equal(t, lastSuccess, report.Checks[1].LastSuccess)
+	assert.Equal(t, []string{"cache"}, report.Failed(false))
+	assert.Empty(t, report.Failed(true))
+	assert.NoError(t, h.Check(ctx))
+
+	// упавшая критичная проверка не отменяет остальные
+	dbErr = errors.New("db unavailable")
+	report = h.Report(ctx)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, []string{"db"}, report.Failed(true))
+	assert.Equal(t, []string{"db", "cache"}, report.Failed(false))
+
+	err := h.Check(ctx)
+	var checkErr *CheckError
+	require.ErrorAs(t, err, &checkErr)
+	assert.Equal(t, "db", checkErr.Name)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, cacheErr)
+}
+
+func TestThresholds(t *testing.T) {
+	ctx := context.Background()
+	var err error
+	h := New(WithDefaults(Thresholds(2, 2)))
+	h.Add("db", func(context.Context) error { return err })
+
+	status := func() Status { return h.Report(ctx).Checks[0].Status }
+	assert.Equal(t, StatusUp, status())
+
+	// одна ошибка не меняет состояние
+	err = errors.New("timeout")
+	assert.Equal(t, StatusUp, status())
+	assert.ErrorIs(t, h.Check(ctx), err)
+	assert.Equal(t, StatusDown, status())
+
+	// восстановление тоже после двух успехов подряд
+	err = nil
+	assert.Equal(t, StatusDown, status())
+	assert.Equal(t, StatusUp, status())
+	assert.Empty(t, h.Report(ctx).Checks[0].Error)
+}
+
+func TestPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	h := New(WithOverrides(func(name string) []CheckOption {
+		if name == "db" {
+			return []CheckOption{Interval(10 * time.Millisecond)}
+		}
+		return nil
+	}))
+	h.Add("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	h.Start(ctx)
+
+	require.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+	// запросы получают результат последнего опроса, проверка не выполняется
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	stopped := calls.Load()
+	report := h.Report(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.False(t, report.Checks[0].CheckedAt.IsZero())
+	assert.Equal(t, stopped, calls.Load())
+}
@@ -0,0 +1,168 @@
+// Package auth аутентификация HTTP и gRPC запросов по JWT: ключи из JWKS или vault,
+// principal в контексте, публичные маршруты и требуемые scopes
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrNoToken      = errors.New("authorization token is required")
+	ErrInvalidToken = errors.New("invalid authorization token")
+	ErrForbidden    = errors.New("insufficient scope")
+	ErrKeyNotFound  = errors.New("signing key not found")
+)
+
+// Principal аутентифицированный клиент
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Tenant  string
+	Claims  map[string]any // все claims токена
+}
+
+// HasScopes есть все перечисленные scopes
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext principal запроса, false - запрос без токена (публичный маршрут)
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Subject id клиента или пустая строка, подходит для ratelimit.ByContext и idempotency.WithScope
+func Subject(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok {
+		return p.Subject
+	}
+	return ""
+}
+
+// Policy требования к запросу: публичный или нужен токен с указанными scopes
+type Policy struct {
+	Public bool
+	Scopes []string
+}
+
+// Public запрос без токена, если токен передан и валиден - principal все равно доступен
+func Public() Policy {
+	return Policy{Public: true}
+}
+
+// Require нужен токен со всеми перечисленными scopes, без scopes - любой валидный токен
+func Require(scopes ...string) Policy {
+	return Policy{Scopes: scopes}
+}
+
+type rule struct {
+	method  string
+	pattern string
+	policy  Policy
+}
+
+// match шаблон с "/" на конце - префикс, иначе точное совпадение
+func (r rule) match(method, path string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+	if strings.HasSuffix(r.pattern, "/") {
+		return strings.HasPrefix(path, r.pattern)
+	}
+	return path == r.pattern
+}
+
+// Rules политики маршрутов HTTP и методов gRPC, применяется самое длинное совпадение.
+// По умолчанию нужен любой валидный токен, health check и reflection gRPC публичные
+type Rules struct {
+	def  Policy
+	http []rule
+	grpc []rule
+}
+
+func NewRules() *Rules {
+	return &Rules{
+		grpc: []rule{
+			{pattern: "/grpc.health.v1.Health/", policy: Public()},
+			{pattern: "/grpc.reflection.v1.ServerReflection/", policy: Public()},
+			{pattern: "/grpc.reflection.v1alpha.ServerReflection/", policy: Public()},
+		},
+	}
+}
+
+// Default политика для запросов без правила
+func (r *Rules) Default(p Policy) *Rules {
+	r.def = p
+	return r
+}
+
+// Route правило HTTP: "/public/" (префикс), "/payments" (путь), "POST /payments" (метод и путь)
+func (r *Rules) Route(pattern string, p Policy) *Rules {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	r.http = append(r.http, rule{method: method, pattern: path, policy: p})
+	return r
+}
+
+// Method правило gRPC: "/pkg.Service/Method" или весь сервис "/pkg.Service/"
+func (r *Rules) Method(name string, p Policy) *Rules {
+	r.grpc = append(r.grpc, rule{pattern: name, policy: p})
+	return r
+}
+
+func (r *Rules) httpPolicy(method, path string) Policy {
+	return lookup(r.http, r.def, method, path)
+}
+
+func (r *Rules) grpcPolicy(fullMethod string) Policy {
+	return lookup(r.grpc, r.def, "", fullMethod)
+}
+
+func lookup(rules []rule, def Policy, method, path string) Policy {
+	best, length := def, -1
+	for _, rule := range rules {
+		if rule.match(method, path) && len(rule.pattern) > length {
+			best, length = rule.policy, len(rule.pattern)
+		}
+	}
+	return best
+}
+
+// authorize проверяет токен по политике, для публичных запросов невалидный токен игнорируется
+func authorize(ctx context.Context, v *Verifier, policy Policy, token string) (context.Context, string, error) {
+	if token == "" {
+		if policy.Public {
+			return ctx, "public", nil
+		}
+		return ctx, "unauthenticated", ErrNoToken
+	}
+
+	p, err := v.Verify(ctx, token)
+	if err != nil {
+		if policy.Public {
+			return ctx, "public", nil
+		}
+		return ctx, "unauthenticated", err
+	}
+	if !policy.Public && !p.HasScopes(policy.Scopes...) {
+		return ctx, "forbidden", ErrForbidden
+	}
+	return WithPrincipal(ctx, p), "ok", nil
+}
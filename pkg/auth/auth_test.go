@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testKey{kid: kid, key: key}
+}
+
+func (k testKey) public() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: &k.key.PublicKey, KeyID: k.kid, Algorithm: string(jose.RS256), Use: "sig"}
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.Claims, custom map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: k.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), k.kid))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Claims(custom).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.Claims {
+	return jwt.Claims{
+		Subject:  "user-1",
+		Issuer:   "https://id.example.com",
+		Audience: jwt.Audience{"payments"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	key := newTestKey(t, "k1")
+	v := NewVerifier(NewStaticKeys(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key.public()}}), Config{
+		Issuer:   "https://id.example.com",
+		Audience: []string{"payments"},
+	})
+	ctx := context.Background()
+
+	p, err := v.Verify(ctx, key.sign(t, validClaims(), map[string]any{"scope": "payments:read payments:write", "tenant": "acme"}))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+	assert.Equal(t, "acme", p.Tenant)
+	assert.True(t, p.HasScopes("payments:write"))
+	assert.False(t, p.HasScopes("admin"))
+
+	p, err = v.Verify(ctx, key.sign(t, validClaims(), map[string]any{"scp": []string{"admin"}}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, p.Scopes)
+
+	expired := validClaims()
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	_, err = v.Verify(ctx, key.sign(t, expired, nil))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+	_, err = v.Verify(ctx, key.sign(t, wrongIssuer, nil))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// подпись чужим ключом с тем же kid
+	_, err = v.Verify(ctx, newTestKey(t, "k1").sign(t, validClaims(), nil))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.Verify(ctx, "not a token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWKSRotation(t *testing.T) {
+	old, rotated := newTestKey(t, "old"), newTestKey(t, "new")
+	var current atomic.Pointer[jose.JSONWebKeySet]
+	current.Store(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{old.public()}})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(current.Load())
+	}))
+	defer server.Close()
+
+	keys, err := NewJWKS(context.Background(), server.URL, server.Client(), time.Hour)
+	require.NoError(t, err)
+	v := NewVerifier(keys, Config{})
+
+	_, err = v.Verify(context.Background(), old.sign(t, validClaims(), nil))
+	require.NoError(t, err)
+
+	// неизвестный kid перечитывает JWKS, но не чаще minRefetchInterval
+	current.Store(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{old.public(), rotated.public()}})
+	_, err = v.Verify(context.Background(), rotated.sign(t, validClaims(), nil))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	keys.lastLoad = time.Now().Add(-minRefetchInterval)
+	_, err = v.Verify(context.Background(), rotated.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+}
+
+func TestParseKeysPEM(t *testing.T) {
+	key := newTestKey(t, "")
+	der, err := x509.MarshalPKIXPublicKey(&key.key.PublicKey)
+	require.NoError(t, err)
+
+	set, err := ParseKeys(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 1)
+
+	_, err = NewVerifier(NewStaticKeys(set), Config{}).Verify(context.Background(), key.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+
+	_, err = ParseKeys([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestRules(t *testing.T) {
+	rules := NewRules().
+		Route("/public/", Public()).
+		Route("POST /payments", Require("payments:write")).
+		Method("/payments.Payments/", Require("payments:read"))
+
+	assert.True(t, rules.httpPolicy(http.MethodGet, "/public/docs").Public)
+	assert.Equal(t, []string{"payments:write"}, rules.httpPolicy(http.MethodPost, "/payments").Scopes)
+	assert.Equal(t, Policy{}, rules.httpPolicy(http.MethodGet, "/payments"))
+	assert.Equal(t, []string{"payments:read"}, rules.grpcPolicy("/payments.Payments/Get").Scopes)
+	assert.True(t, rules.grpcPolicy("/grpc.health.v1.Health/Check").Public)
+}
+
+func TestMiddleware(t *testing.T) {
+	key := newTestKey(t, "k1")
+	v := NewVerifier(NewStaticKeys(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key.public()}}), Config{})
+	rules := NewRules().Route("/public/", Public()).Route("/admin/", Require("admin"))
+
+	handler := Middleware(v, rules)(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(Subject(r.Context())))
+	})
+	call := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	token := key.sign(t, validClaims(), map[string]any{"scope": "payments:read"})
+
+	rec := call("/payments", token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-1", rec.Body.String())
+
+	rec = call("/payments", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(t, http.StatusForbidden, call("/admin/users", token).Code)
+
+	rec = call("/public/docs", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, http.StatusOK, call("/public/docs", "broken").Code)
+	assert.Equal(t, "user-1", call("/public/docs", token).Body.String())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	key := newTestKey(t, "k1")
+	v := NewVerifier(NewStaticKeys(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key.public()}}), Config{})
+	interceptor := UnaryServerInterceptor(v, NewRules().Method("/admin.Admin/", Require("admin")))
+	handler := func(ctx context.Context, req any) (any, error) {
+		return Subject(ctx), nil
+	}
+	ctx := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	token := key.sign(t, validClaims(), nil)
+
+	resp, err := interceptor(ctx(token), nil, &grpc.UnaryServerInfo{FullMethod: "/payments.Payments/Get"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", resp)
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/payments.Payments/Get"}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = interceptor(ctx(token), nil, &grpc.UnaryServerInfo{FullMethod: "/admin.Admin/Delete"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	assert.NoError(t, err)
+}
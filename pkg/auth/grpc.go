@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor токен из metadata authorization: Bearer.
+// Нет или невалидный токен - Unauthenticated, не хватает scopes - PermissionDenied
+func UnaryServerInterceptor(v *Verifier, rules *Rules) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorizeGRPC(ctx, v, rules, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(v *Verifier, rules *Rules) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorizeGRPC(ss.Context(), v, rules, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorizeGRPC(ctx context.Context, v *Verifier, rules *Rules, method string) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = bearer(values[0])
+		}
+	}
+
+	ctx, result, err := authorize(ctx, v, rules.grpcPolicy(method), token)
+	metrics.AuthRequestsTotal.WithLabelValues("grpc", result).Inc()
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return ctx, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return ctx, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+	}
+	return ctx, nil
+}
+
+// serverStream стрим с контекстом, в котором есть principal
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
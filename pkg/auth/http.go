@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+// Middleware токен из заголовка Authorization: Bearer, principal кладется в контекст запроса.
+// Нет или невалидный токен - 401, не хватает scopes - 403
+func Middleware(v *Verifier, rules *Rules) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			policy := rules.httpPolicy(r.Method, r.URL.Path)
+			ctx, result, err := authorize(r.Context(), v, policy, bearer(r.Header.Get("Authorization")))
+			metrics.AuthRequestsTotal.WithLabelValues("http", result).Inc()
+			if err != nil {
+				if errors.Is(err, ErrForbidden) {
+					w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+					http.Error(w, err.Error(), http.StatusForbidden)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				http.Error(w, ErrInvalidToken.Error(), http.StatusUnauthorized)
+				return
+			}
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+func bearer(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	DefaultRefreshInterval = 5 * time.Minute
+	// minRefetchInterval неизвестный kid перечитывает ключи не чаще, чтобы мусорные токены не нагружали JWKS
+	minRefetchInterval = 10 * time.Second
+	maxJWKSSize        = 1 << 20
+)
+
+// KeySet ключи проверки подписи по kid
+type KeySet interface {
+	Keys(ctx context.Context, kid string) ([]jose.JSONWebKey, error)
+}
+
+// StaticKeys неизменяемый набор ключей
+type StaticKeys struct {
+	set jose.JSONWebKeySet
+}
+
+func NewStaticKeys(set jose.JSONWebKeySet) *StaticKeys {
+	return &StaticKeys{set: set}
+}
+
+func (s *StaticKeys) Keys(_ context.Context, kid string) ([]jose.JSONWebKey, error) {
+	if kid == "" {
+		return s.set.Keys, nil
+	}
+	if keys := s.set.Key(kid); len(keys) > 0 {
+		return keys, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+// ParseKeys JWKS в JSON или публичные ключи в PEM, kid ключей PEM не задан
+func ParseKeys(data []byte) (jose.JSONWebKeySet, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err == nil && len(set.Keys) > 0 {
+		return set, nil
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return set, fmt.Errorf("parse public key: %w", err)
+		}
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: key, Use: "sig"})
+	}
+	if len(set.Keys) == 0 {
+		return set, errors.New("no keys found")
+	}
+	return set, nil
+}
+
+// LoadFunc загрузка актуального набора ключей
+type LoadFunc func(ctx context.Context) (jose.JSONWebKeySet, error)
+
+// CachedKeys ключи в памяти, обновляются раз в interval и при неизвестном kid (ротация ключей).
+// Ошибка обновления логируется, продолжают использоваться предыдущие ключи
+type CachedKeys struct {
+	name     string
+	load     LoadFunc
+	interval time.Duration
+	keys     atomic.Pointer[StaticKeys]
+
+	mu       sync.Mutex
+	lastLoad time.Time
+}
+
+// NewCachedKeys загружает ключи, ошибка первой загрузки возвращается
+func NewCachedKeys(ctx context.Context, name string, load LoadFunc, interval time.Duration) (*CachedKeys, error) {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	c := &CachedKeys{name: name, load: load, interval: interval}
+	if err := c.Reload(ctx); err != nil {
+		return nil, fmt.Errorf("load %s keys: %w", name, err)
+	}
+	return c, nil
+}
+
+// NewJWKS ключи с JWKS endpoint провайдера (keycloak, auth0 и т.п.)
+func NewJWKS(ctx context.Context, url string, client *http.Client, interval time.Duration) (*CachedKeys, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return NewCachedKeys(ctx, url, func(ctx context.Context) (jose.JSONWebKeySet, error) {
+		var set jose.JSONWebKeySet
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return set, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return set, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return set, fmt.Errorf("jwks status %d", resp.StatusCode)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+		if err != nil {
+			return set, err
+		}
+		return ParseKeys(body)
+	}, interval)
+}
+
+// KVLoader чтение секрета KV v2, реализуется vault.VaultClient
+type KVLoader interface {
+	LoadKV(ctx context.Context, mount, path string) (map[string]interface{}, error)
+}
+
+// NewVaultKeys ключи из секрета vault: поле jwks (JSON) или public_key (PEM)
+func NewVaultKeys(ctx context.Context, client KVLoader, mount, path string, interval time.Duration) (*CachedKeys, error) {
+	return NewCachedKeys(ctx, "vault:"+path, func(ctx context.Context) (jose.JSONWebKeySet, error) {
+		data, err := client.LoadKV(ctx, mount, path)
+		if err != nil {
+			return jose.JSONWebKeySet{}, err
+		}
+		for _, field := range []string{"jwks", "public_key"} {
+			if value, _ := data[field].(string); value != "" {
+				return ParseKeys([]byte(value))
+			}
+		}
+		return jose.JSONWebKeySet{}, fmt.Errorf("vault secret %s: jwks or public_key is required", path)
+	}, interval)
+}
+
+// Run обновляет ключи до отмены ctx
+func (c *CachedKeys) Run(ctx context.Context) {
+	ctx = logger.With(ctx, logger.String("keys", c.name))
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reload(ctx); err != nil {
+				logger.Error(ctx, "Failed to reload signing keys, using previous", logger.Err(err))
+			}
+		}
+	}
+}
+
+// Reload перечитывает ключи
+func (c *CachedKeys) Reload(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reload(ctx)
+}
+
+func (c *CachedKeys) reload(ctx context.Context) error {
+	c.lastLoad = time.Now()
+	set, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+	c.keys.Store(NewStaticKeys(set))
+	return nil
+}
+
+func (c *CachedKeys) Keys(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	keys, err := c.keys.Load().Keys(ctx, kid)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return keys, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.lastLoad) >= minRefetchInterval {
+		if err := c.reload(ctx); err != nil {
+			logger.Error(ctx, "Failed to reload signing keys", logger.String("keys", c.name), logger.Err(err))
+		}
+	}
+	return c.keys.Load().Keys(ctx, kid)
+}
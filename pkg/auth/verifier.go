@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+var defaultAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.ES256, jose.ES384, jose.EdDSA}
+
+// Config проверки токена. Пустые Issuer и Audience не проверяются
+type Config struct {
+	Issuer      string
+	Audience    []string
+	Leeway      time.Duration // допустимое расхождение часов, по умолчанию 1m
+	ScopeClaim  string        // по умолчанию scope (строка через пробел) или scp (массив)
+	TenantClaim string        // по умолчанию tenant
+}
+
+// Verifier проверяет подпись и claims токена
+type Verifier struct {
+	keys KeySet
+	cfg  Config
+}
+
+func NewVerifier(keys KeySet, cfg Config) *Verifier {
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = jwt.DefaultLeeway
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	return &Verifier{keys: keys, cfg: cfg}
+}
+
+// Verify проверяет подпись, срок действия, issuer и audience, возвращает principal
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Principal, error) {
+	token, err := jwt.ParseSigned(raw, defaultAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	keys, err := v.keys.Keys(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var (
+		claims jwt.Claims
+		custom map[string]any
+	)
+	err = ErrKeyNotFound
+	for _, key := range keys {
+		if err = token.Claims(key.Key, &claims, &custom); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	expected := jwt.Expected{Issuer: v.cfg.Issuer, AnyAudience: v.cfg.Audience, Time: time.Now()}
+	if err := claims.ValidateWithLeeway(expected, v.cfg.Leeway); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	tenant, _ := custom[v.cfg.TenantClaim].(string)
+	return &Principal{
+		Subject: claims.Subject,
+		Scopes:  v.scopes(custom),
+		Tenant:  tenant,
+		Claims:  custom,
+	}, nil
+}
+
+func (v *Verifier) scopes(claims map[string]any) []string {
+	names := []string{"scope", "scp"}
+	if v.cfg.ScopeClaim != "" {
+		names = []string{v.cfg.ScopeClaim}
+	}
+	for _, name := range names {
+		switch value := claims[name].(type) {
+		case string:
+			return strings.Fields(value)
+		case []any:
+			scopes := make([]string, 0, len(value))
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+			return scopes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// buildinfo версия сборки приложения, задается через ldflags:
+//
+//	go build -ldflags "-X git.vepay.dev/knoknok/backend-platform/pkg/buildinfo.Version=1.4.2 \
+//		-X git.vepay.dev/knoknok/backend-platform/pkg/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//		-X git.vepay.dev/knoknok/backend-platform/pkg/buildinfo.BuildTime=$(date -u +%FT%TZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает информацию о сборке, если коммит не задан через ldflags,
+// берется vcs.revision, который go build записывает в бинарь
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					info.Commit = s.Value
+				case "vcs.time":
+					if info.BuildTime == "" {
+						info.BuildTime = s.Value
+					}
+				}
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
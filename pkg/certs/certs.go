@@ -0,0 +1,242 @@
+// Package certs загрузка TLS сертификатов из файлов или vault с периодическим обновлением без рестарта
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+const DefaultReloadInterval = time.Minute
+
+var (
+	ErrInvalidCA           = errors.New("no certificates found in CA bundle")
+	ErrClientCANotLoaded   = errors.New("client CA is not configured")
+	ErrClientCertRequired  = errors.New("client certificate required")
+	ErrVaultFieldNotFound  = errors.New("field not found in vault secret")
+	ErrCertificateNotFound = errors.New("certificate not loaded")
+)
+
+// Bundle сертификат сервера и CA для проверки клиентских сертификатов
+type Bundle struct {
+	Certificate tls.Certificate
+	ClientCAs   *x509.CertPool // nil, если CA не задан
+}
+
+// Source источник сертификатов
+type Source interface {
+	Load(ctx context.Context) (*Bundle, error)
+}
+
+type fileSource struct {
+	certFile, keyFile, caFile string
+}
+
+// Files сертификат и ключ в PEM, caFile - CA клиентов для mTLS, может быть пустым
+func Files(certFile, keyFile, caFile string) Source {
+	return &fileSource{certFile: certFile, keyFile: keyFile, caFile: caFile}
+}
+
+func (s *fileSource) Load(context.Context) (*Bundle, error) {
+	certPEM, err := os.ReadFile(s.certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(s.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	var caPEM []byte
+	if s.caFile != "" {
+		if caPEM, err = os.ReadFile(s.caFile); err != nil {
+			return nil, fmt.Errorf("failed to read CA: %w", err)
+		}
+	}
+	return Parse(certPEM, keyPEM, caPEM)
+}
+
+// KVLoader чтение секрета KV v2, реализуется vault.VaultClient
+type KVLoader interface {
+	LoadKV(ctx context.Context, mount, path string) (map[string]interface{}, error)
+}
+
+type vaultSource struct {
+	client      KVLoader
+	mount, path string
+}
+
+// Vault секрет KV с полями certificate, private_key и необязательным ca
+func Vault(client KVLoader, mount, path string) Source {
+	return &vaultSource{client: client, mount: mount, path: path}
+}
+
+func (s *vaultSource) Load(ctx context.Context) (*Bundle, error) {
+	data, err := s.client.LoadKV(ctx, s.mount, s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) []byte {
+		value, _ := data[name].(string)
+		return []byte(value)
+	}
+	certPEM, keyPEM := field("certificate"), field("private_key")
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("%w: certificate and private_key are required in %s", ErrVaultFieldNotFound, s.path)
+	}
+	return Parse(certPEM, keyPEM, field("ca"))
+}
+
+// Parse собирает Bundle из PEM, caPEM может быть пустым
+func Parse(certPEM, keyPEM, caPEM []byte) (*Bundle, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key pair: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+	}
+
+	bundle := &Bundle{Certificate: cert}
+	if len(caPEM) > 0 {
+		bundle.ClientCAs = x509.NewCertPool()
+		if !bundle.ClientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, ErrInvalidCA
+		}
+	}
+	return bundle, nil
+}
+
+// Reloader хранит текущий сертификат и периодически перечитывает источник.
+// Ошибка обновления логируется, продолжает использоваться предыдущий сертификат
+type Reloader struct {
+	name     string
+	source   Source
+	interval time.Duration
+	bundle   atomic.Pointer[Bundle]
+}
+
+// NewReloader загружает сертификат, ошибка первой загрузки возвращается
+func NewReloader(ctx context.Context, name string, source Source, interval time.Duration) (*Reloader, error) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	r := &Reloader{name: name, source: source, interval: interval}
+
+	bundle, err := source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.store(bundle)
+	return r, nil
+}
+
+// Run обновляет сертификат до отмены ctx
+func (r *Reloader) Run(ctx context.Context) {
+	ctx = logger.With(ctx, logger.String("certificate", r.name))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reload(ctx)
+		}
+	}
+}
+
+// Reload перечитывает источник, возвращает true если сертификат изменился
+func (r *Reloader) Reload(ctx context.Context) bool {
+	bundle, err := r.source.Load(ctx)
+	if err != nil {
+		logger.Error(ctx, "Failed to reload certificate, using previous", logger.Err(err))
+		return false
+	}
+
+	current := r.bundle.Load()
+	if bytes.Equal(current.Certificate.Certificate[0], bundle.Certificate.Certificate[0]) &&
+		(current.ClientCAs == nil) == (bundle.ClientCAs == nil) &&
+		(current.ClientCAs == nil || current.ClientCAs.Equal(bundle.ClientCAs)) {
+		return false
+	}
+
+	r.store(bundle)
+	logger.Info(ctx, "Certificate reloaded", logger.Time("not_after", bundle.Certificate.Leaf.NotAfter))
+	return true
+}
+
+func (r *Reloader) store(bundle *Bundle) {
+	r.bundle.Store(bundle)
+	metrics.TLSCertificateExpiry.WithLabelValues(r.name).Set(float64(bundle.Certificate.Leaf.NotAfter.Unix()))
+}
+
+// Bundle текущий сертификат
+func (r *Reloader) Bundle() *Bundle {
+	return r.bundle.Load()
+}
+
+// TLSConfig конфиг сервера, сертификат и CA клиентов берутся из последней загрузки.
+// clientAuth: tls.NoClientCert, tls.VerifyClientCertIfGiven или tls.RequireAndVerifyClientCert
+func (r *Reloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			bundle := r.bundle.Load()
+			if bundle == nil {
+				return nil, ErrCertificateNotFound
+			}
+			return &bundle.Certificate, nil
+		},
+	}
+
+	// клиентский сертификат проверяется вручную, чтобы CA можно было обновлять без пересоздания конфига
+	switch clientAuth {
+	case tls.RequireAndVerifyClientCert, tls.RequireAnyClientCert:
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = r.verifyClient(true)
+	case tls.VerifyClientCertIfGiven, tls.RequestClientCert:
+		cfg.ClientAuth = tls.RequestClientCert
+		cfg.VerifyConnection = r.verifyClient(false)
+	}
+	return cfg
+}
+
+func (r *Reloader) verifyClient(required bool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			if required {
+				return ErrClientCertRequired
+			}
+			return nil
+		}
+
+		bundle := r.bundle.Load()
+		if bundle == nil || bundle.ClientCAs == nil {
+			return ErrClientCANotLoaded
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         bundle.ClientCAs,
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
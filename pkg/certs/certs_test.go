@@ -0,0 +1,162 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyPair struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func issue(t *testing.T, name string, parent *keyPair, usage x509.ExtKeyUsage) *keyPair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	} else {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &keyPair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestReloaderFiles(t *testing.T) {
+	ctx := context.Background()
+	ca := issue(t, "ca", nil, 0)
+	first := issue(t, "first", ca, x509.ExtKeyUsageServerAuth)
+	second := issue(t, "second", ca, x509.ExtKeyUsageServerAuth)
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	write := func(pair *keyPair) {
+		require.NoError(t, os.WriteFile(certFile, pair.certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, pair.keyPEM, 0o600))
+	}
+	write(first)
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	r, err := NewReloader(ctx, "test", Files(certFile, keyFile, caFile), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "first", r.Bundle().Certificate.Leaf.Subject.CommonName)
+	assert.NotNil(t, r.Bundle().ClientCAs)
+
+	assert.False(t, r.Reload(ctx))
+
+	write(second)
+	assert.True(t, r.Reload(ctx))
+	assert.Equal(t, "second", r.Bundle().Certificate.Leaf.Subject.CommonName)
+
+	// битый файл не заменяет текущий сертификат
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	assert.False(t, r.Reload(ctx))
+	assert.Equal(t, "second", r.Bundle().Certificate.Leaf.Subject.CommonName)
+}
+
+type kvStub map[string]interface{}
+
+func (s kvStub) LoadKV(context.Context, string, string) (map[string]interface{}, error) {
+	return s, nil
+}
+
+func TestVaultSource(t *testing.T) {
+	ca := issue(t, "ca", nil, 0)
+	server := issue(t, "server", ca, x509.ExtKeyUsageServerAuth)
+
+	bundle, err := Vault(kvStub{
+		"certificate": string(server.certPEM),
+		"private_key": string(server.keyPEM),
+	}, "kv", "certs/app").Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "server", bundle.Certificate.Leaf.Subject.CommonName)
+	assert.Nil(t, bundle.ClientCAs)
+
+	_, err = Vault(kvStub{"certificate": string(server.certPEM)}, "kv", "certs/app").Load(context.Background())
+	assert.ErrorIs(t, err, ErrVaultFieldNotFound)
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := issue(t, "ca", nil, 0)
+	server := issue(t, "server", ca, x509.ExtKeyUsageServerAuth)
+	client := issue(t, "client", ca, x509.ExtKeyUsageClientAuth)
+	stranger := issue(t, "stranger", issue(t, "other-ca", nil, 0), x509.ExtKeyUsageClientAuth)
+
+	bundle, err := Parse(server.certPEM, server.keyPEM, ca.certPEM)
+	require.NoError(t, err)
+	r := &Reloader{name: "mtls"}
+	r.store(bundle)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = r.TLSConfig(tls.RequireAndVerifyClientCert)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(pair *keyPair) error {
+		tlsCfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if pair != nil {
+			cert, err := tls.X509KeyPair(pair.certPEM, pair.keyPEM)
+			require.NoError(t, err)
+			tlsCfg.Certificates = []tls.Certificate{cert}
+		}
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	assert.NoError(t, get(client))
+	assert.Error(t, get(nil))
+	assert.Error(t, get(stranger))
+}
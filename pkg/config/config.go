@@ -17,6 +17,8 @@ import (
 
 var (
 	configInstance *config.Config
+	vaultInstance  *vault.VaultClient
+	consulInstance consul.Client
 	once           sync.Once
 )
 
@@ -31,6 +33,21 @@ func GetConfig() config.Configurer {
 	return configInstance
 }
 
+// GetVaultClient клиент vault, созданный в Init, nil если vault отключен
+func GetVaultClient() *vault.VaultClient {
+	return vaultInstance
+}
+
+// GetConsulClient клиент consul, созданный в Init, nil если consul отключен
+func GetConsulClient() consul.Client {
+	return consulInstance
+}
+
+// GetVaultMount mount секретов KV из VAULT_MOUNT_PATH, по умолчанию kv
+func GetVaultMount() string {
+	return getVaultMount()
+}
+
 func Init(ctx context.Context, opts ...InitOption) error {
 	var err error
 	once.Do(func() {
@@ -54,6 +71,7 @@ func Init(ctx context.Context, opts ...InitOption) error {
 		}
 
 		if consulClient != nil {
+			consulInstance = consulClient
 			sharedPrefix := cfg.GetStringOrDefault(envConsulSharedPrefix, defaultConsulSharedPrefix)
 			appPrefix := cfg.GetStringOrDefault(envConsulAppPrefix, appName)
 			consulShared := consulprovider.NewProvider(sharedPrefix, consulClient)
@@ -74,6 +92,7 @@ func Init(ctx context.Context, opts ...InitOption) error {
 		}
 
 		if vaultClient != nil {
+			vaultInstance = vaultClient
 			mount := getVaultMount()
 			appPath := cfg.GetStringOrDefault(envVaultAppPath, appName)
 			sharedPath := cfg.GetStringOrDefault(envVaultSharedPath, defaultVaultSharedPrefix)
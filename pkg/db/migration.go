@@ -6,7 +6,7 @@ import (
 	"os"
 	"time"
 
-	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas-go-sdk/atlasexec"
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
 )
 
@@ -1,9 +1,12 @@
 package di
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -11,16 +14,28 @@ import (
 type Container interface {
 	// Build инициализирует фабрики и запускает процесс инжектирования
 	Build() error
+	// Services возвращает зарегистрированные сервисы, используется для диагностики
+	Services() []Service
+}
+
+// Service описание зарегистрированного сервиса
+type Service struct {
+	Type     string `json:"type"`     // тип, под которым зарегистрирован сервис
+	Name     string `json:"name"`     // имя RegisterNamed, пустое у сервиса по умолчанию
+	Impl     string `json:"impl"`     // тип реализации
+	Injected bool   `json:"injected"` // зависимости ResolveDeps внедрены
 }
 
 type dependency struct {
+	typeName string
+	name     string
 	service  any
 	injected bool
 }
 
 type containerImpl struct {
 	mu           sync.RWMutex
-	dependencies map[string]dependency // сервисы по имени пакета и типа
+	dependencies map[string]dependency // сервисы по имени пакета и типа, именованные - с суффиксом имени
 	initialized  bool                  // флаг инициализации
 }
 
@@ -49,41 +64,70 @@ func (c *containerImpl) Build() error {
 	return nil
 }
 
-func (c *containerImpl) exist(typeName string) error {
-	if _, exists := c.dependencies[typeName]; exists {
-		return fmt.Errorf("%w in services for type_name: %s", ErrAlreadyRegistered, typeName)
+func (c *containerImpl) Services() []Service {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	services := make([]Service, 0, len(c.dependencies))
+	for _, dep := range c.dependencies {
+		services = append(services, Service{
+			Type:     dep.typeName,
+			Name:     dep.name,
+			Impl:     fmt.Sprintf("%T", dep.service),
+			Injected: dep.injected,
+		})
+	}
+	slices.SortFunc(services, func(a, b Service) int {
+		return cmp.Or(strings.Compare(a.Type, b.Type), strings.Compare(a.Name, b.Name))
+	})
+	return services
+}
+
+func (c *containerImpl) exist(key string) error {
+	if _, exists := c.dependencies[key]; exists {
+		return fmt.Errorf("%w in services for type_name: %s", ErrAlreadyRegistered, key)
 	}
 	return nil
 }
 
 func (c *containerImpl) build() error {
 	var err error
-	for _, dep := range c.dependencies {
+	for key, dep := range c.dependencies {
 		if dep.injected {
 			continue
 		}
-		ierr := c.inject(dep.service)
-		err = errors.Join(err, ierr)
+		if ierr := c.inject(dep.service, true); ierr != nil {
+			err = errors.Join(err, ierr)
+			continue
+		}
+		dep.injected = true
+		c.dependencies[key] = dep
 	}
 
 	return err
 }
 
-func (c *containerImpl) getDependency(paramType reflect.Type) (dependency, error) {
+// getDependency именованный сервис, если его нет и fallback - сервис по умолчанию
+func (c *containerImpl) getDependency(paramType reflect.Type, name string, fallback bool) (dependency, error) {
 	typeName := getTypeName(paramType)
 
-	dep, exists := c.dependencies[typeName]
-	if !exists {
-		return dependency{}, fmt.Errorf("%w: %s", ErrUnregisteredType, paramType.Name())
+	if dep, exists := c.dependencies[dependencyKey(typeName, name)]; exists {
+		return dep, nil
+	}
+	if dep, exists := c.dependencies[typeName]; exists && (name == "" || fallback) {
+		return dep, nil
+	}
+	if name != "" {
+		return dependency{}, fmt.Errorf("%w: %s named %q", ErrUnregisteredType, paramType.Name(), name)
 	}
-	return dep, nil
+	return dependency{}, fmt.Errorf("%w: %s", ErrUnregisteredType, paramType.Name())
 }
 
-func (c *containerImpl) resolve(paramType reflect.Type) (any, error) {
+func (c *containerImpl) resolve(paramType reflect.Type, name string) (any, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	dep, err := c.getDependency(paramType)
+	dep, err := c.getDependency(paramType, name, true)
 	if err != nil {
 		return nil, err
 	}
@@ -91,41 +135,47 @@ func (c *containerImpl) resolve(paramType reflect.Type) (any, error) {
 	return dep.service, nil
 }
 
-func (c *containerImpl) register(typeInfo reflect.Type, instance any) error {
+func (c *containerImpl) register(typeInfo reflect.Type, name string, instance any) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	typeName := getTypeName(typeInfo)
-	if err := c.exist(typeName); err != nil {
+	key := dependencyKey(typeName, name)
+	if err := c.exist(key); err != nil {
 		panic(err)
 	}
 
+	dep := dependency{typeName: typeName, name: name, service: instance}
 	if c.initialized {
-		if err := c.inject(instance); err != nil {
+		if err := c.inject(instance, true); err != nil {
 			return err
 		}
-		c.dependencies[typeName] = dependency{service: instance, injected: true}
+		dep.injected = true
+		c.dependencies[key] = dep
 		return nil
 	}
 
-	// пробуем инжектить параметры
-	err := c.inject(instance)
+	// пробуем инжектить параметры, именованный сервис может быть зарегистрирован позже,
+	// поэтому до Build сервис по умолчанию вместо него не подставляется
+	err := c.inject(instance, false)
 	if err == nil {
-		c.dependencies[typeName] = dependency{service: instance, injected: true}
+		dep.injected = true
+		c.dependencies[key] = dep
 		return nil
 	}
 
 	// если не удалось собрать все зависимости, то ждем Build
 	if errors.Is(err, ErrUnregisteredType) {
-		c.dependencies[typeName] = dependency{service: instance, injected: false}
+		c.dependencies[key] = dep
 		return nil
 	}
 
 	return err
 }
 
-// inject находит метод ResolveDeps у переданного инстанса и внедряет зависимости
-func (c *containerImpl) inject(instance interface{}) error {
+// inject находит метод ResolveDeps у переданного инстанса и внедряет зависимости,
+// fallback - параметр Named без сервиса с таким именем получает сервис по умолчанию
+func (c *containerImpl) inject(instance interface{}, fallback bool) error {
 	val := reflect.ValueOf(instance)
 	if val.Kind() != reflect.Ptr {
 		return fmt.Errorf("%w, got %T", ErrServiceMustBePointer, instance)
@@ -143,11 +193,24 @@ func (c *containerImpl) inject(instance interface{}) error {
 	for i := 0; i < numIn; i++ {
 		paramType := methodType.In(i)
 
-		dep, err := c.getDependency(paramType)
+		// параметр di.Named[T, Q] получает сервис T с именем Q
+		var named namedParam
+		target, name := paramType, ""
+		if reflect.PointerTo(paramType).Implements(namedParamType) {
+			named = reflect.New(paramType).Interface().(namedParam)
+			target, name = named.diType(), named.diName()
+		}
+
+		dep, err := c.getDependency(target, name, fallback)
 		if err != nil {
 			return fmt.Errorf("failed to resolve dependency for %s, param %d, %w", reflect.TypeOf(instance), i, err)
 		}
 
+		if named != nil {
+			named.diSet(dep.service)
+			args[i] = reflect.ValueOf(named).Elem()
+			continue
+		}
 		args[i] = reflect.ValueOf(dep.service)
 	}
 
@@ -165,6 +228,14 @@ func (c *containerImpl) inject(instance interface{}) error {
 	return nil
 }
 
+// dependencyKey ключ сервиса: имя типа, для именованных - с именем через #
+func dependencyKey(typeName, name string) string {
+	if name == "" {
+		return typeName
+	}
+	return typeName + "#" + name
+}
+
 // getTypeName возвращает имя типа для использования в качестве ключа
 func getTypeName(typeOf reflect.Type) string {
 	if typeOf.Kind() == reflect.Ptr {
@@ -71,7 +71,20 @@ func Register[T any](ctx context.Context, instance T) T {
 	container := getContainer(ctx)
 	c := container.(*containerImpl)
 
-	if err := c.register(reflect.TypeFor[T](), instance); err != nil {
+	if err := c.register(reflect.TypeFor[T](), "", instance); err != nil {
+		panic(err)
+	}
+
+	return instance
+}
+
+// RegisterNamed регистрирует еще один инстанс того же типа под именем,
+// например основную и отчетную базу. Имя должно быть уникально для типа
+func RegisterNamed[T any](ctx context.Context, name string, instance T) T {
+	container := getContainer(ctx)
+	c := container.(*containerImpl)
+
+	if err := c.register(reflect.TypeFor[T](), name, instance); err != nil {
 		panic(err)
 	}
 
@@ -86,7 +99,7 @@ func RegisterFactory[T any](ctx context.Context, factory func() T) {
 
 	instance := factory()
 
-	if err := c.register(reflect.TypeFor[T](), instance); err != nil {
+	if err := c.register(reflect.TypeFor[T](), "", instance); err != nil {
 		panic(err)
 	}
 }
@@ -95,10 +108,16 @@ func RegisterFactory[T any](ctx context.Context, factory func() T) {
 // можно вызывать до Build, но в таком случае будут возвращаться только те сервисы
 // которые были уже зарегистрированы ранее
 func Resolve[T any](ctx context.Context) T {
+	return ResolveNamed[T](ctx, "")
+}
+
+// ResolveNamed возвращает инстанс, зарегистрированный через RegisterNamed,
+// если под таким именем ничего нет - инстанс по умолчанию из Register
+func ResolveNamed[T any](ctx context.Context, name string) T {
 	container := getContainer(ctx)
 	c := container.(*containerImpl)
 
-	inst, err := c.resolve(reflect.TypeFor[T]())
+	inst, err := c.resolve(reflect.TypeFor[T](), name)
 	if err != nil {
 		panic(fmt.Errorf("failed to resolve instance %w", err))
 	}
@@ -3,6 +3,7 @@ package di
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	clickdb "git.vepay.dev/knoknok/backend-platform/internal/pkg/mock/db/click/db"
@@ -209,6 +210,107 @@ func TestRegisterFactoryTwiceErr(t *testing.T) {
 	})
 }
 
+func TestRegisterNamed(t *testing.T) {
+	testContainer := New()
+	ctx := WithContainer(context.Background(), testContainer)
+
+	Register[pgdb.IUserRepository](ctx, &pgdb.UserRepository{User: "primary"})
+	RegisterNamed[pgdb.IUserRepository](ctx, "reporting", &pgdb.UserRepository{User: "reporting"})
+	Register[*reportService](ctx, &reportService{})
+
+	requirePanicsWithMessage(t, "already registered in services", func() {
+		RegisterNamed[pgdb.IUserRepository](ctx, "reporting", &pgdb.UserRepository{})
+	})
+
+	require.NoError(t, testContainer.Build())
+
+	assert.Equal(t, "primary", Resolve[pgdb.IUserRepository](ctx).GetProfile())
+	assert.Equal(t, "reporting", ResolveNamed[pgdb.IUserRepository](ctx, "reporting").GetProfile())
+	// имени нет - сервис по умолчанию
+	assert.Equal(t, "primary", ResolveNamed[pgdb.IUserRepository](ctx, "archive").GetProfile())
+
+	svc := Resolve[*reportService](ctx)
+	assert.Equal(t, "reporting", svc.reporting.GetProfile())
+	assert.Equal(t, "primary", svc.archive.GetProfile())
+
+	services := testContainer.Services()
+	require.Len(t, services, 3)
+	findService(t, services, "*db.UserRepository", "")
+	findService(t, services, "*db.UserRepository", "reporting")
+	assert.True(t, findService(t, services, "*di.reportService", "").Injected)
+
+	requirePanicsWithMessage(t, `named "reporting"`, func() {
+		ResolveNamed[clickdb.IUserRepository](ctx, "reporting")
+	})
+}
+
+func TestRegisterNamedAfterDependent(t *testing.T) {
+	testContainer := New()
+	ctx := WithContainer(context.Background(), testContainer)
+
+	// именованный сервис регистрируется после сервиса, которому он нужен
+	Register[pgdb.IUserRepository](ctx, &pgdb.UserRepository{User: "primary"})
+	svc := Register[*reportService](ctx, &reportService{})
+	assert.Nil(t, svc.reporting)
+	RegisterNamed[pgdb.IUserRepository](ctx, "reporting", &pgdb.UserRepository{User: "reporting"})
+
+	require.NoError(t, testContainer.Build())
+	assert.Equal(t, "reporting", svc.reporting.GetProfile())
+	assert.Equal(t, "primary", svc.archive.GetProfile())
+	assert.True(t, findService(t, testContainer.Services(), "*di.reportService", "").Injected)
+}
+
+func TestNamedPointerQualifier(t *testing.T) {
+	testContainer := New()
+	ctx := WithContainer(context.Background(), testContainer)
+
+	Register[pgdb.IUserRepository](ctx, &pgdb.UserRepository{User: "primary"})
+	RegisterNamed[pgdb.IUserRepository](ctx, "replica", &pgdb.UserRepository{User: "replica"})
+	svc := Register[*replicaService](ctx, &replicaService{})
+
+	require.NoError(t, testContainer.Build())
+	assert.Equal(t, "replica", svc.replica.GetProfile())
+}
+
+// findService сервис по реализации и имени, порядок Services не гарантирован
+func findService(t *testing.T, services []Service, impl, name string) Service {
+	t.Helper()
+	i := slices.IndexFunc(services, func(s Service) bool { return s.Impl == impl && s.Name == name })
+	require.NotEqual(t, -1, i, "service %s %q not found", impl, name)
+	return services[i]
+}
+
+type reportingDB struct{}
+
+func (reportingDB) Qualifier() string { return "reporting" }
+
+type archiveDB struct{}
+
+func (archiveDB) Qualifier() string { return "archive" }
+
+type reportService struct {
+	reporting pgdb.IUserRepository
+	archive   pgdb.IUserRepository
+}
+
+func (s *reportService) ResolveDeps(reporting Named[pgdb.IUserRepository, reportingDB], archive Named[pgdb.IUserRepository, archiveDB]) {
+	s.reporting = reporting.Value
+	s.archive = archive.Value
+}
+
+type replicaDB struct{}
+
+func (replicaDB) Qualifier() string { return "replica" }
+
+type replicaService struct {
+	replica pgdb.IUserRepository
+}
+
+// квалификатор указателем: метод с получателем-значением нельзя вызвать на nil
+func (s *replicaService) ResolveDeps(replica Named[pgdb.IUserRepository, *replicaDB]) {
+	s.replica = replica.Value
+}
+
 type nonPointerUserRepository struct {
 	User string
 }
@@ -0,0 +1,49 @@
+package di
+
+import "reflect"
+
+// Qualifier тип-метка имени для параметров ResolveDeps
+//
+//	type Reporting struct{}
+//
+//	func (Reporting) Qualifier() string { return "reporting" }
+type Qualifier interface {
+	Qualifier() string
+}
+
+// Named параметр ResolveDeps, получает сервис T, зарегистрированный через RegisterNamed
+// с именем Q.Qualifier(), или сервис по умолчанию, если такого имени нет.
+// Q может быть указателем, Qualifier вызывается на новом значении
+//
+//	func (s *reportService) ResolveDeps(db di.Named[db.DbClient, Reporting]) {
+//		s.db = db.Value
+//	}
+type Named[T any, Q Qualifier] struct {
+	Value T
+}
+
+// namedParam отличает Named от обычных параметров ResolveDeps
+type namedParam interface {
+	diType() reflect.Type
+	diName() string
+	diSet(service any)
+}
+
+var namedParamType = reflect.TypeFor[namedParam]()
+
+func (n *Named[T, Q]) diType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (n *Named[T, Q]) diName() string {
+	// у нулевого указателя метод с получателем-значением паникует, поэтому для *Q создается значение
+	if t := reflect.TypeFor[Q](); t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(Q).Qualifier()
+	}
+	var q Q
+	return q.Qualifier()
+}
+
+func (n *Named[T, Q]) diSet(service any) {
+	n.Value = service.(T)
+}
@@ -0,0 +1,195 @@
+// Package flags фиче-флаги: включение по проценту клиентов, списки разрешенных и запрещенных id,
+// определения хранятся в consul KV и обновляются без перезапуска
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	"git.vepay.dev/knoknok/backend-platform/pkg/di"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+// Flags вычисление флагов для клиента из контекста, регистрируется в di
+type Flags interface {
+	IsEnabled(ctx context.Context, name string) bool
+	String(ctx context.Context, name, def string) string
+	Int(ctx context.Context, name string, def int) int
+	Float64(ctx context.Context, name string, def float64) float64
+	Duration(ctx context.Context, name string, def time.Duration) time.Duration
+}
+
+// Flag определение флага. Выключенный или отсутствующий флаг - false и значение по умолчанию.
+// Для включенного: id из Deny - выключен, из Allow - включен, иначе включен для Rollout процентов клиентов
+type Flag struct {
+	Enabled bool            `json:"enabled"`
+	Rollout *float64        `json:"rollout,omitempty"` // 0-100, по умолчанию 100
+	By      string          `json:"by,omitempty"`      // user (по умолчанию) или tenant
+	Allow   []string        `json:"allow,omitempty"`   // id пользователей или tenant
+	Deny    []string        `json:"deny,omitempty"`
+	Value   json.RawMessage `json:"value,omitempty"` // значение для String, Int и т.п. при включенном флаге
+}
+
+// Target клиент, для которого вычисляется флаг
+type Target struct {
+	User   string
+	Tenant string
+}
+
+type targetKey struct{}
+
+// WithTarget задает клиента явно, например в воркерах и консьюмерах без principal
+func WithTarget(ctx context.Context, t Target) context.Context {
+	return context.WithValue(ctx, targetKey{}, t)
+}
+
+// TargetFromContext клиент из WithTarget или из principal аутентификации
+func TargetFromContext(ctx context.Context) Target {
+	if t, ok := ctx.Value(targetKey{}).(Target); ok {
+		return t
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		return Target{User: p.Subject, Tenant: p.Tenant}
+	}
+	return Target{}
+}
+
+// Store текущие определения флагов в памяти, заменяются целиком при обновлении источника
+type Store struct {
+	flags atomic.Pointer[map[string]Flag]
+}
+
+func NewStore() *Store {
+	s := &Store{}
+	s.Set(nil)
+	return s
+}
+
+// Set заменяет все определения
+func (s *Store) Set(flags map[string]Flag) {
+	if flags == nil {
+		flags = map[string]Flag{}
+	}
+	s.flags.Store(&flags)
+}
+
+// Get определение флага
+func (s *Store) Get(name string) (Flag, bool) {
+	flag, ok := (*s.flags.Load())[name]
+	return flag, ok
+}
+
+func (s *Store) IsEnabled(ctx context.Context, name string) bool {
+	_, on := s.evaluate(ctx, name)
+	return on
+}
+
+func (s *Store) String(ctx context.Context, name, def string) string {
+	return value(ctx, s, name, def)
+}
+
+func (s *Store) Int(ctx context.Context, name string, def int) int {
+	return value(ctx, s, name, def)
+}
+
+func (s *Store) Float64(ctx context.Context, name string, def float64) float64 {
+	return value(ctx, s, name, def)
+}
+
+// Duration значение в формате "1m30s"
+func (s *Store) Duration(ctx context.Context, name string, def time.Duration) time.Duration {
+	str := value(ctx, s, name, "")
+	if str == "" {
+		return def
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+func value[T any](ctx context.Context, s *Store, name string, def T) T {
+	flag, on := s.evaluate(ctx, name)
+	if !on || len(flag.Value) == 0 {
+		return def
+	}
+	var v T
+	if err := json.Unmarshal(flag.Value, &v); err != nil {
+		return def
+	}
+	return v
+}
+
+func (s *Store) evaluate(ctx context.Context, name string) (Flag, bool) {
+	flag, ok := s.Get(name)
+	on := ok && flag.on(name, TargetFromContext(ctx))
+
+	result := "off"
+	if on {
+		result = "on"
+	}
+	if !ok {
+		result = "missing"
+	}
+	metrics.FeatureFlagEvaluationsTotal.WithLabelValues(name, result).Inc()
+	return flag, on
+}
+
+func (f Flag) on(name string, t Target) bool {
+	if !f.Enabled {
+		return false
+	}
+	if slices.Contains(f.Deny, t.User) || slices.Contains(f.Deny, t.Tenant) {
+		return false
+	}
+	if (t.User != "" && slices.Contains(f.Allow, t.User)) || (t.Tenant != "" && slices.Contains(f.Allow, t.Tenant)) {
+		return true
+	}
+	if f.Rollout == nil || *f.Rollout >= 100 {
+		return true
+	}
+
+	id := t.User
+	if f.By == "tenant" {
+		id = t.Tenant
+	}
+	if id == "" || *f.Rollout <= 0 {
+		return false
+	}
+	return bucket(name, id) < *f.Rollout
+}
+
+// bucket стабильное значение 0-100 для пары флаг и клиент: клиент не прыгает между вариантами,
+// а разные флаги включаются у разных клиентов
+func bucket(name, id string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name + ":" + id))
+	return float64(h.Sum64()%10000) / 100
+}
+
+// IsEnabled флаг из di контейнера контекста
+func IsEnabled(ctx context.Context, name string) bool {
+	return di.Resolve[Flags](ctx).IsEnabled(ctx, name)
+}
+
+func String(ctx context.Context, name, def string) string {
+	return di.Resolve[Flags](ctx).String(ctx, name, def)
+}
+
+func Int(ctx context.Context, name string, def int) int {
+	return di.Resolve[Flags](ctx).Int(ctx, name, def)
+}
+
+func Float64(ctx context.Context, name string, def float64) float64 {
+	return di.Resolve[Flags](ctx).Float64(ctx, name, def)
+}
+
+func Duration(ctx context.Context, name string, def time.Duration) time.Duration {
+	return di.Resolve[Flags](ctx).Duration(ctx, name, def)
+}
@@ -0,0 +1,138 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/auth"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rollout(v float64) *float64 {
+	return &v
+}
+
+func TestFlagEvaluation(t *testing.T) {
+	store := NewStore()
+	store.Set(map[string]Flag{
+		"off":      {Enabled: false, Allow: []string{"u1"}},
+		"on":       {Enabled: true},
+		"lists":    {Enabled: true, Rollout: rollout(0), Allow: []string{"u1", "acme"}, Deny: []string{"u2"}},
+		"denied":   {Enabled: true, Deny: []string{"blocked"}},
+		"tenant":   {Enabled: true, Rollout: rollout(50), By: "tenant"},
+		"settings": {Enabled: true, Value: []byte(`{"limit": 10}`)},
+		"limit":    {Enabled: true, Value: []byte(`25`)},
+		"timeout":  {Enabled: true, Value: []byte(`"1m30s"`)},
+		"provider": {Enabled: true, Value: []byte(`"stripe"`)},
+	})
+
+	user := func(id, tenant string) context.Context {
+		return WithTarget(context.Background(), Target{User: id, Tenant: tenant})
+	}
+
+	assert.False(t, store.IsEnabled(user("u1", ""), "off"))
+	assert.False(t, store.IsEnabled(user("u1", ""), "missing"))
+	assert.True(t, store.IsEnabled(context.Background(), "on"))
+
+	assert.True(t, store.IsEnabled(user("u1", ""), "lists"))
+	assert.True(t, store.IsEnabled(user("u3", "acme"), "lists"))
+	assert.False(t, store.IsEnabled(user("u3", ""), "lists"))
+	assert.False(t, store.IsEnabled(user("u2", "acme"), "lists"))
+	assert.False(t, store.IsEnabled(user("", "blocked"), "denied"))
+
+	// rollout по tenant одинаков для всех пользователей tenant
+	enabled := store.IsEnabled(user("a", "t1"), "tenant")
+	assert.Equal(t, enabled, store.IsEnabled(user("b", "t1"), "tenant"))
+	assert.False(t, store.IsEnabled(user("a", ""), "tenant"))
+
+	assert.Equal(t, 25, store.Int(context.Background(), "limit", 5))
+	assert.Equal(t, 5, store.Int(context.Background(), "missing", 5))
+	assert.Equal(t, 5, store.Int(context.Background(), "provider", 5))
+	assert.Equal(t, "stripe", store.String(context.Background(), "provider", "paypal"))
+	assert.Equal(t, 90*time.Second, store.Duration(context.Background(), "timeout", time.Second))
+	assert.Equal(t, 1.5, store.Float64(context.Background(), "off", 1.5))
+}
+
+func TestRolloutDistribution(t *testing.T) {
+	store := NewStore()
+	store.Set(map[string]Flag{"new-checkout": {Enabled: true, Rollout: rollout(25)}})
+
+	enabled := 0
+	for i := range 10000 {
+		ctx := WithTarget(context.Background(), Target{User: fmt.Sprintf("user-%d", i)})
+		if store.IsEnabled(ctx, "new-checkout") {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 2500, enabled, 200)
+}
+
+func TestTargetFromPrincipal(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "u1", Tenant: "acme"})
+	assert.Equal(t, Target{User: "u1", Tenant: "acme"}, TargetFromContext(ctx))
+
+	ctx = WithTarget(ctx, Target{User: "worker"})
+	assert.Equal(t, Target{User: "worker"}, TargetFromContext(ctx))
+}
+
+type fakeKV struct {
+	pairs    api.KVPairs
+	callback func(api.KVPairs)
+}
+
+func (f *fakeKV) GetConfig(string) (api.KVPairs, error) {
+	return f.pairs, nil
+}
+
+func (f *fakeKV) WatchPrefix(_ context.Context, _ string, callback func(api.KVPairs)) error {
+	f.callback = callback
+	return nil
+}
+
+func TestLoadConsul(t *testing.T) {
+	kv := &fakeKV{pairs: api.KVPairs{
+		{Key: "flags/payments/new-checkout", Value: []byte(`true`)},
+		{Key: "flags/payments/limits", Value: []byte(`{"enabled": true, "value": 10}`)},
+		{Key: "flags/payments/broken", Value: []byte(`{`)},
+		{Key: "flags/payments/", Value: nil},
+	}}
+	store := NewStore()
+	require.NoError(t, LoadConsul(context.Background(), store, kv, "flags/payments"))
+
+	assert.True(t, store.IsEnabled(context.Background(), "new-checkout"))
+	assert.Equal(t, 10, store.Int(context.Background(), "limits", 0))
+	_, ok := store.Get("broken")
+	assert.False(t, ok)
+
+	kv.callback(api.KVPairs{{Key: "flags/payments/new-checkout", Value: []byte(`false`)}})
+	assert.False(t, store.IsEnabled(context.Background(), "new-checkout"))
+	_, ok = store.Get("limits")
+	assert.False(t, ok)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+new-checkout: true
+limits:
+  enabled: true
+  rollout: 100
+  value:
+    max: 10
+`), 0o600))
+
+	store := NewStore()
+	require.NoError(t, LoadFile(store, path))
+	assert.True(t, store.IsEnabled(context.Background(), "new-checkout"))
+
+	flag, _ := store.Get("limits")
+	assert.JSONEq(t, `{"max": 10}`, string(flag.Value))
+
+	assert.ErrorIs(t, LoadFile(store, filepath.Join(t.TempDir(), "missing.yaml")), os.ErrNotExist)
+}
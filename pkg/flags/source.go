@@ -0,0 +1,99 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"github.com/hashicorp/consul/api"
+	"go.yaml.in/yaml/v3"
+)
+
+// KV consul KV, реализуется consul.Client
+type KV interface {
+	GetConfig(prefix string) (api.KVPairs, error)
+	WatchPrefix(ctx context.Context, prefix string, callback func(api.KVPairs)) error
+}
+
+// LoadConsul загружает флаги из prefix и обновляет store при изменениях.
+// Ключ <prefix>/<name>, значение - JSON Flag или true/false. Некорректные значения пропускаются с ошибкой в логе
+func LoadConsul(ctx context.Context, store *Store, kv KV, prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+
+	pairs, err := kv.GetConfig(prefix)
+	if err != nil {
+		return fmt.Errorf("load flags %s: %w", prefix, err)
+	}
+	store.Set(parsePairs(ctx, prefix, pairs))
+
+	return kv.WatchPrefix(ctx, prefix, func(pairs api.KVPairs) {
+		flags := parsePairs(ctx, prefix, pairs)
+		store.Set(flags)
+		logger.Info(ctx, "Feature flags updated", logger.Int("count", len(flags)))
+	})
+}
+
+func parsePairs(ctx context.Context, prefix string, pairs api.KVPairs) map[string]Flag {
+	flags := make(map[string]Flag, len(pairs))
+	for _, pair := range pairs {
+		name := strings.TrimPrefix(pair.Key, prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		flag, err := parseFlag(pair.Value)
+		if err != nil {
+			logger.Error(ctx, "Invalid feature flag", logger.String("flag", name), logger.Err(err))
+			continue
+		}
+		flags[name] = flag
+	}
+	return flags
+}
+
+func parseFlag(data []byte) (Flag, error) {
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(string(data))); err == nil {
+		return Flag{Enabled: enabled}, nil
+	}
+	var flag Flag
+	err := json.Unmarshal(data, &flag)
+	return flag, err
+}
+
+// LoadFile флаги из локального yaml или json файла для разработки: имя флага -> определение
+func LoadFile(store *Store, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(data, &raw)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return fmt.Errorf("parse flags %s: %w", path, err)
+	}
+
+	flags := make(map[string]Flag, len(raw))
+	for name, def := range raw {
+		// yaml разбирается в map, значение value приводится к JSON через повторную сериализацию
+		data, err := json.Marshal(def)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", name, err)
+		}
+		flag, err := parseFlag(data)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", name, err)
+		}
+		flags[name] = flag
+	}
+	store.Set(flags)
+	return nil
+}
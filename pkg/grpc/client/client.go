@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"slices"
 	"sync"
 	"time"
 )
@@ -31,6 +32,7 @@ type Config struct {
 	MaxSendMsgSize   int
 	KeepAliveTime    time.Duration
 	KeepAliveTimeout time.Duration
+	Health           HealthCheckConfig
 }
 
 type registration struct {
@@ -42,6 +44,7 @@ type registration struct {
 type Manager struct {
 	mu                 sync.RWMutex
 	connections        map[string]*grpc.ClientConn
+	probes             map[string]HealthCheckConfig // клиенты с активной проверкой
 	registrations      []registration
 	unaryInterceptors  []grpc.UnaryClientInterceptor
 	streamInterceptors []grpc.StreamClientInterceptor
@@ -50,6 +53,7 @@ type Manager struct {
 func NewManager() *Manager {
 	return &Manager{
 		connections:        make(map[string]*grpc.ClientConn),
+		probes:             make(map[string]HealthCheckConfig),
 		unaryInterceptors:  make([]grpc.UnaryClientInterceptor, 0),
 		streamInterceptors: make([]grpc.StreamClientInterceptor, 0),
 	}
@@ -99,6 +103,9 @@ func (m *Manager) Initialize(ctx context.Context, resolveCfg func(service string
 		if cfg.Address == "" {
 			return fmt.Errorf("address not configured for service: %s", reg.serviceName)
 		}
+		if !slices.Contains([]string{HealthModeNone, HealthModeState, HealthModeRPC}, cfg.Health.Mode) {
+			return fmt.Errorf("unknown health mode %q for service: %s", cfg.Health.Mode, reg.serviceName)
+		}
 
 		opts := []grpc.DialOption{
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -127,6 +134,9 @@ func (m *Manager) Initialize(ctx context.Context, resolveCfg func(service string
 
 		m.mu.Lock()
 		m.connections[reg.serviceName] = conn
+		if cfg.Health.Mode != HealthModeNone {
+			m.probes[reg.serviceName] = cfg.Health
+		}
 		m.mu.Unlock()
 
 		inst := reg.build(conn)
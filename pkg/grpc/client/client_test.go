@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"git.vepay.dev/knoknok/backend-platform/pkg/di"
 	"google.golang.org/grpc"
@@ -119,3 +120,97 @@ func TestClient_AddGenericRegistration_PanicsOnBadConstructor(t *testing.T) {
 	bad := func(_ grpc.ClientConnInterface) string { return "nope" }
 	AddGenericRegistration[int](m, "bad", bad)
 }
+
+// в di тип клиента регистрируется один раз
+type (
+	rpcUpstream   struct{}
+	stateUpstream struct{}
+	deadUpstream  struct{}
+	plainUpstream struct{}
+)
+
+func addUpstream[T any](m *Manager, name string) {
+	AddGenericRegistration[*T](m, name, func(grpc.ClientConnInterface) *T { return new(T) })
+}
+
+func TestClient_Probe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testCtx(t), 5*time.Second)
+	defer cancel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, hs)
+	hs.SetServingStatus("test.v1.Echo", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	go s.Serve(l)
+	defer s.Stop()
+
+	// свободный порт, на котором никто не слушает
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	deadAddr := dead.Addr().String()
+	_ = dead.Close()
+
+	m := NewManager()
+	addUpstream[rpcUpstream](m, "rpc")
+	addUpstream[stateUpstream](m, "state")
+	addUpstream[deadUpstream](m, "dead")
+	addUpstream[plainUpstream](m, "plain")
+	resolve := func(service string) Config {
+		cfg := Config{Address: l.Addr().String(), MaxRecvMsgSize: 4 << 20, MaxSendMsgSize: 4 << 20}
+		switch service {
+		case "rpc":
+			cfg.Health = HealthCheckConfig{Mode: HealthModeRPC, Service: "test.v1.Echo"}
+		case "state":
+			cfg.Health = HealthCheckConfig{Mode: HealthModeState}
+		case "dead":
+			cfg.Address = deadAddr
+			cfg.Health = HealthCheckConfig{Mode: HealthModeState}
+		}
+		return cfg
+	}
+	if err := m.Initialize(ctx, resolve); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer m.Close()
+
+	if got := len(m.Probes()); got != 3 {
+		t.Fatalf("Probes: got %d, want 3", got)
+	}
+
+	if err := m.Probe(ctx, "rpc"); !errors.Is(err, ErrUpstreamNotServing) {
+		t.Fatalf("rpc probe: want ErrUpstreamNotServing, got %v", err)
+	}
+	hs.SetServingStatus("test.v1.Echo", grpc_health_v1.HealthCheckResponse_SERVING)
+	if err := m.Probe(ctx, "rpc"); err != nil {
+		t.Fatalf("rpc probe: %v", err)
+	}
+
+	if err := m.Probe(ctx, "state"); err != nil {
+		t.Fatalf("state probe: %v", err)
+	}
+	if err := m.Probe(ctx, "dead"); !errors.Is(err, ErrUpstreamNotServing) {
+		t.Fatalf("dead probe: want ErrUpstreamNotServing, got %v", err)
+	}
+
+	// старая проверка соединений не видит недоступный сервис
+	if err := m.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+}
+
+func TestClient_Initialize_UnknownHealthMode(t *testing.T) {
+	m := NewManager()
+	AddGenericRegistration[grpc_health_v1.HealthClient](m, "health", grpc_health_v1.NewHealthClient)
+	err := m.Initialize(testCtx(t), func(string) Config {
+		return Config{Address: "127.0.0.1:1", Health: HealthCheckConfig{Mode: "tcp"}}
+	})
+	if err == nil {
+		t.Fatalf("want error on unknown health mode")
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Режимы активной проверки вышестоящего сервиса
+const (
+	HealthModeNone  = ""      // только проверка что соединение не закрыто
+	HealthModeState = "state" // соединение устанавливается и переходит в READY
+	HealthModeRPC   = "rpc"   // grpc.health.v1.Health/Check отвечает SERVING
+)
+
+var ErrUpstreamNotServing = errors.New("upstream is not serving")
+
+// HealthCheckConfig активная проверка вышестоящего сервиса
+type HealthCheckConfig struct {
+	Mode     string
+	Service  string        // сервис в запросе Health/Check, "" - общий статус сервера
+	Interval time.Duration // период фоновой проверки
+	Timeout  time.Duration
+	Critical bool // упавший сервис делает приложение не готовым, иначе только degraded
+}
+
+// Probes клиенты с активной проверкой и их настройки
+func (m *Manager) Probes() map[string]HealthCheckConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	probes := make(map[string]HealthCheckConfig, len(m.probes))
+	for service, cfg := range m.probes {
+		probes[service] = cfg
+	}
+	return probes
+}
+
+// Probe активная проверка клиента, результат публикуется в grpc_client_upstream_up
+func (m *Manager) Probe(ctx context.Context, service string) error {
+	m.mu.RLock()
+	conn, ok := m.connections[service]
+	cfg := m.probes[service]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("connection not found for service: %s", service)
+	}
+
+	err := probe(ctx, conn, cfg)
+	up, result := 1.0, "ok"
+	if err != nil {
+		up, result = 0, "error"
+	}
+	metrics.GrpcClientUpstreamUp.WithLabelValues(service).Set(up)
+	metrics.GrpcClientHealthChecksTotal.WithLabelValues(service, result).Inc()
+	return err
+}
+
+func probe(ctx context.Context, conn *grpc.ClientConn, cfg HealthCheckConfig) error {
+	switch cfg.Mode {
+	case HealthModeState:
+		return waitReady(ctx, conn)
+	case HealthModeRPC:
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: cfg.Service})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%w: %s", ErrUpstreamNotServing, resp.GetStatus())
+		}
+		return nil
+	default:
+		if conn.GetState() == connectivity.Shutdown {
+			return errors.New("connection is shutdown")
+		}
+		return nil
+	}
+}
+
+// waitReady ленивое соединение подключается и ждет READY, TRANSIENT_FAILURE сразу считается ошибкой
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("%w: connection state %s", ErrUpstreamNotServing, state)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%w: connection state %s: %w", ErrUpstreamNotServing, state, ctx.Err())
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"net"
 	"reflect"
+	"slices"
 	"sync"
 	"time"
 )
@@ -39,6 +40,7 @@ type Manager struct {
 	listener      net.Listener
 	grpc          *grpc.Server
 	health        *health.Server
+	services      []string // зарегистрированные сервисы, заполняются в Initialize
 	mu            sync.Mutex
 	registrations []func(grpc.ServiceRegistrar)
 
@@ -46,6 +48,8 @@ type Manager struct {
 	streamInterceptors []grpc.StreamServerInterceptor
 
 	publicServices map[uintptr]struct{}
+
+	onError func(error) // обработчик фатальной ошибки Serve
 }
 
 func New(cfg Config) *Manager {
@@ -119,8 +123,16 @@ func (m *Manager) Initialize(ctx context.Context) error {
 		reg(m.grpc)
 	}
 
+	// собственный статус у каждого сервиса, кроме самого health
+	for name := range m.grpc.GetServiceInfo() {
+		if name != grpc_health_v1.Health_ServiceDesc.ServiceName {
+			m.services = append(m.services, name)
+		}
+	}
+	slices.Sort(m.services)
+
 	// общая health
-	m.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	m.SetAllServing(true)
 
 	logger.Info(ctx, "gRPC services registered",
 		logger.Int("count", len(m.registrations)),
@@ -139,19 +151,35 @@ func (m *Manager) Start(ctx context.Context) error {
 	logger.Info(ctx, "gRPC server starting", logger.String("addr", m.addr))
 
 	go func() {
+		// после Stop/GracefulStop Serve возвращает nil, ошибка означает падение сервера
 		if err := m.grpc.Serve(l); err != nil {
 			logger.Error(ctx, "gRPC server error", logger.Err(err))
+			if m.onError != nil {
+				m.onError(err)
+			}
 		}
 	}()
 	return nil
 }
 
+// OnError устанавливает обработчик фатальной ошибки сервера, вызывать до Start
+func (m *Manager) OnError(fn func(error)) {
+	m.onError = fn
+}
+
 func (m *Manager) Stop() error {
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), timestop)
+	defer cancel()
+	return m.Shutdown(ctx)
+}
+
+// Shutdown ждет завершения активных запросов до истечения ctx, затем останавливает сервер принудительно
+func (m *Manager) Shutdown(ctx context.Context) error {
 	logger.Info(ctx, "gRPC server stopping")
 
+	// все сервисы NOT_SERVING, дальнейшие изменения статуса игнорируются
 	if m.health != nil {
-		m.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		m.health.Shutdown()
 	}
 
 	stopped := make(chan struct{})
@@ -163,13 +191,38 @@ func (m *Manager) Stop() error {
 	select {
 	case <-stopped:
 		logger.Info(ctx, "gRPC server stopped gracefully")
-	case <-time.After(timestop):
+	case <-ctx.Done():
 		logger.Warn(ctx, "gRPC server stop timeout, forcing shutdown")
 		m.grpc.Stop()
 	}
 	return nil
 }
 
+// Services сервисы сервера без grpc.health.v1.Health, доступны после Initialize
+func (m *Manager) Services() []string {
+	return m.services
+}
+
+// SetServingStatus статус сервиса в grpc health, "" - общий статус сервера
+func (m *Manager) SetServingStatus(service string, serving bool) {
+	if m.health == nil {
+		return
+	}
+	status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	m.health.SetServingStatus(service, status)
+}
+
+// SetAllServing общий статус и статус всех сервисов
+func (m *Manager) SetAllServing(serving bool) {
+	m.SetServingStatus("", serving)
+	for _, service := range m.services {
+		m.SetServingStatus(service, serving)
+	}
+}
+
 func (m *Manager) HealthCheck(ctx context.Context) error {
 	if m.grpc == nil {
 		return fmt.Errorf("gRPC server not initialized")
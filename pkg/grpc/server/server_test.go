@@ -121,3 +121,52 @@ func TestServer_Start_Fails(t *testing.T) {
 		t.Fatalf("want error on Start when port is busy")
 	}
 }
+
+func TestServer_ServiceHealth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addr := freePort(t)
+	m := New(testServerConfig(addr))
+	m.AddService(func(s grpc.ServiceRegistrar) {
+		s.RegisterService(&grpc.ServiceDesc{ServiceName: "test.v1.Echo", HandlerType: (*any)(nil)}, struct{}{})
+	})
+	if err := m.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if got := m.Services(); len(got) != 1 || got[0] != "test.v1.Echo" {
+		t.Fatalf("Services: got %v", got)
+	}
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	hc := grpc_health_v1.NewHealthClient(conn)
+
+	status := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := hc.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("health check %q: %v", service, err)
+		}
+		return resp.GetStatus()
+	}
+
+	if got := status("test.v1.Echo"); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("want SERVING after Initialize, got %v", got)
+	}
+
+	// статус сервиса меняется независимо от общего
+	m.SetServingStatus("test.v1.Echo", false)
+	if got := status("test.v1.Echo"); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want NOT_SERVING, got %v", got)
+	}
+	if got := status(""); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("want overall SERVING, got %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package httproute шаблон маршрута запроса (/users/{id}) для меток метрик и имен спанов вместо сырого пути
+package httproute
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Other метка запросов без шаблона маршрута (404) и сверх лимита Limiter
+const Other = "other"
+
+type holderKey struct{}
+
+type holder struct {
+	pattern string
+}
+
+// WithHolder добавляет в контекст место для шаблона, который заполняет роутер через Set
+func WithHolder(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(holderKey{}).(*holder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, holderKey{}, &holder{})
+}
+
+// Set сохраняет шаблон маршрута, без WithHolder ничего не делает
+func Set(ctx context.Context, pattern string) {
+	if h, ok := ctx.Value(holderKey{}).(*holder); ok && pattern != "" {
+		h.pattern = pattern
+	}
+}
+
+// Get шаблон маршрута или пустая строка, если роутер его не сообщил
+func Get(ctx context.Context) string {
+	if h, ok := ctx.Value(holderKey{}).(*holder); ok {
+		return h.pattern
+	}
+	return ""
+}
+
+// ServeMux сообщает шаблон http.ServeMux (Go 1.22+), метод и хост из шаблона отбрасываются:
+// "GET /users/{id}" -> "/users/{id}"
+func ServeMux(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// defer, чтобы шаблон был известен и при панике обработчика
+		defer func() { Set(r.Context(), PatternPath(r.Pattern)) }()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// PatternPath путь из шаблона ServeMux без метода и хоста
+func PatternPath(pattern string) string {
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = strings.TrimSpace(pattern[i+1:])
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// Middleware сообщает шаблон, который роутер знает после маршрутизации, например chi:
+//
+//	r.Use(httproute.Middleware(func(r *http.Request) string {
+//		return chi.RouteContext(r.Context()).RoutePattern()
+//	}))
+func Middleware(pattern func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() { Set(r.Context(), pattern(r)) }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MiddlewareEcho сообщает шаблон маршрута echo (/users/:id), подключается через e.Use
+func MiddlewareEcho() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			Set(c.Request().Context(), c.Path())
+			return next(c)
+		}
+	}
+}
+
+// Limiter ограничивает число различных меток, новые шаблоны сверх лимита заменяются на Other
+type Limiter struct {
+	max  int
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// NewLimiter max <= 0 - без ограничения
+func NewLimiter(max int) *Limiter {
+	return &Limiter{max: max, seen: make(map[string]struct{})}
+}
+
+// Label метка для шаблона: пустой шаблон и шаблоны сверх лимита - Other
+func (l *Limiter) Label(pattern string) string {
+	if pattern == "" {
+		return Other
+	}
+	if l == nil || l.max <= 0 {
+		return pattern
+	}
+
+	l.mu.RLock()
+	_, ok := l.seen[pattern]
+	l.mu.RUnlock()
+	if ok {
+		return pattern
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[pattern]; ok {
+		return pattern
+	}
+	if len(l.seen) >= l.max {
+		return Other
+	}
+	l.seen[pattern] = struct{}{}
+	return pattern
+}
@@ -0,0 +1,74 @@
+package httproute
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve выполняет запрос и возвращает шаблон, который сообщил роутер
+func serve(handler http.Handler, path string) string {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r = r.WithContext(WithHolder(r.Context()))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return Get(r.Context())
+}
+
+func TestServeMux(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("/files/", func(http.ResponseWriter, *http.Request) {})
+
+	handler := ServeMux(mux)
+	assert.Equal(t, "/users/{id}", serve(handler, "/users/123"))
+	assert.Equal(t, "/files/", serve(handler, "/files/a/b"))
+	assert.Equal(t, "", serve(handler, "/unknown"))
+}
+
+func TestPatternPath(t *testing.T) {
+	assert.Equal(t, "/users/{id}", PatternPath("GET /users/{id}"))
+	assert.Equal(t, "/users/{id}", PatternPath("GET example.com/users/{id}"))
+	assert.Equal(t, "/", PatternPath("/"))
+	assert.Equal(t, "", PatternPath(""))
+}
+
+func TestMiddleware(t *testing.T) {
+	// шаблон известен только после маршрутизации, как в chi
+	handler := Middleware(func(r *http.Request) string { return "/orders/{id}" })(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") }))
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	r = r.WithContext(WithHolder(r.Context()))
+	assert.Panics(t, func() { handler.ServeHTTP(httptest.NewRecorder(), r) })
+	assert.Equal(t, "/orders/{id}", Get(r.Context()))
+}
+
+func TestMiddlewareEcho(t *testing.T) {
+	e := echo.New()
+	e.Use(MiddlewareEcho())
+	e.GET("/users/:id", func(c echo.Context) error { return nil })
+
+	assert.Equal(t, "/users/:id", serve(e, "/users/123"))
+}
+
+func TestWithoutHolder(t *testing.T) {
+	ctx := context.Background()
+	Set(ctx, "/users/{id}")
+	assert.Equal(t, "", Get(ctx))
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2)
+	assert.Equal(t, "/a", l.Label("/a"))
+	assert.Equal(t, "/b", l.Label("/b"))
+	assert.Equal(t, Other, l.Label("/c"))
+	assert.Equal(t, "/a", l.Label("/a"))
+	assert.Equal(t, Other, l.Label(""))
+
+	var unlimited *Limiter
+	assert.Equal(t, "/c", unlimited.Label("/c"))
+}
@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	metadataKey      = "idempotency-key"
+	metadataReplayed = "idempotent-replayed"
+)
+
+// UnaryServerInterceptor применяется к вызовам с metadata idempotency-key.
+// Отпечаток - метод и сообщение запроса. Сохраняются успешные ответы и ошибки клиента
+// (InvalidArgument, NotFound, AlreadyExists, FailedPrecondition, OutOfRange), остальные ошибки освобождают ключ.
+// Повтор с тем же ключом и другим запросом - InvalidArgument, пока первый вызов выполняется - Aborted
+func UnaryServerInterceptor(s *Store) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(metadataKey)
+		msg, ok := req.(proto.Message)
+		if len(values) == 0 || !ok {
+			return handler(ctx, req)
+		}
+
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		lease, stored, err := s.Begin(ctx, values[0], Fingerprint([]byte(info.FullMethod), data))
+		if err != nil {
+			metrics.IdempotencyRequestsTotal.WithLabelValues(result(err)).Inc()
+			return nil, grpcError(ctx, err)
+		}
+		if stored != nil {
+			metrics.IdempotencyRequestsTotal.WithLabelValues("replayed").Inc()
+			_ = grpc.SetHeader(ctx, metadata.Pairs(metadataReplayed, "true"))
+			return decode(stored)
+		}
+		metrics.IdempotencyRequestsTotal.WithLabelValues("new").Inc()
+
+		ctx = context.WithoutCancel(ctx)
+		defer func() {
+			if p := recover(); p != nil {
+				release(ctx, lease)
+				panic(p)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		stored, ok = encode(resp, err)
+		if !ok {
+			release(ctx, lease)
+			return resp, err
+		}
+		if err := lease.Complete(ctx, *stored); err != nil {
+			logger.Error(ctx, "Idempotency response not saved", logger.Err(err))
+		}
+		return resp, err
+	}
+}
+
+func grpcError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidKey), errors.Is(err, ErrKeyReused):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		logger.Error(ctx, "Idempotency store unavailable", logger.Err(err))
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+func encode(resp any, err error) (*Response, bool) {
+	if err != nil {
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange:
+			return &Response{Code: uint32(st.Code()), Message: st.Message()}, true
+		}
+		return nil, false
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	return &Response{Type: string(msg.ProtoReflect().Descriptor().FullName()), Body: body}, true
+}
+
+func decode(stored *Response) (any, error) {
+	if stored.Code != 0 {
+		return nil, status.Error(codes.Code(stored.Code), stored.Message)
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(stored.Type))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	msg := mt.New().Interface()
+	if err := proto.Unmarshal(stored.Body, msg); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return msg, nil
+}
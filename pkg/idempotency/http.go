@@ -0,0 +1,168 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+// Middleware применяется к запросам с заголовком Idempotency-Key.
+// Отпечаток - метод, путь с query и тело. Ответы 5xx, 401, 403, 409 и 429 не сохраняются, повтор выполнит запрос заново.
+// Повтор с тем же ключом и другим запросом - 422, пока первый запрос выполняется - 409
+func Middleware(s *Store) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next(w, r)
+				return
+			}
+			ctx := r.Context()
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, int64(s.maxBody)+1))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if len(body) > s.maxBody {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			lease, stored, err := s.Begin(ctx, key, Fingerprint([]byte(r.Method), []byte(r.URL.RequestURI()), body))
+			if err != nil {
+				metrics.IdempotencyRequestsTotal.WithLabelValues(result(err)).Inc()
+				http.Error(w, err.Error(), httpStatus(ctx, err))
+				return
+			}
+			if stored != nil {
+				metrics.IdempotencyRequestsTotal.WithLabelValues("replayed").Inc()
+				replay(w, stored)
+				return
+			}
+			metrics.IdempotencyRequestsTotal.WithLabelValues("new").Inc()
+
+			// клиент мог отключиться, ключ все равно нужно завершить
+			ctx = context.WithoutCancel(ctx)
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, max: s.maxBody}
+			defer func() {
+				if p := recover(); p != nil {
+					release(ctx, lease)
+					panic(p)
+				}
+			}()
+
+			next(rec, r)
+
+			if !cacheable(rec.status) || rec.overflow {
+				release(ctx, lease)
+				return
+			}
+			if err := lease.Complete(ctx, Response{Status: rec.status, Header: w.Header().Clone(), Body: rec.body.Bytes()}); err != nil {
+				logger.Error(ctx, "Idempotency response not saved", logger.Err(err))
+			}
+		}
+	}
+}
+
+func httpStatus(ctx context.Context, err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidKey):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrInProgress):
+		return http.StatusConflict
+	default:
+		// без хранилища нельзя гарантировать однократное выполнение
+		logger.Error(ctx, "Idempotency store unavailable", logger.Err(err))
+		return http.StatusServiceUnavailable
+	}
+}
+
+func result(err error) string {
+	switch {
+	case errors.Is(err, ErrKeyReused):
+		return "reused"
+	case errors.Is(err, ErrInProgress):
+		return "in_progress"
+	case errors.Is(err, ErrInvalidKey):
+		return "invalid"
+	default:
+		return "error"
+	}
+}
+
+// cacheable отказы auth, конфликт и лимиты зависят от момента запроса, а не от операции
+func cacheable(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
+
+// replay заголовки, выставленные мидлварями до обработчика (request id и т.п.), не перезаписываются
+func replay(w http.ResponseWriter, resp *Response) {
+	header := w.Header()
+	for name, values := range resp.Header {
+		if _, ok := header[name]; !ok {
+			header[name] = values
+		}
+	}
+	header.Set(HeaderReplayed, "true")
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
+
+func release(ctx context.Context, lease *Lease) {
+	if err := lease.Release(ctx); err != nil {
+		logger.Error(ctx, "Idempotency key not released", logger.Err(err))
+	}
+}
+
+// responseRecorder пишет ответ клиенту и копирует его для сохранения
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	max         int
+	overflow    bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(b) > r.max {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,238 @@
+// Package idempotency повторные запросы с тем же ключом идемпотентности получают сохраненный ответ
+// вместо повторного выполнения операции. Ответы и отпечатки запросов хранятся в redis
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPrefix  = "idempotency:"
+	defaultTTL     = 24 * time.Hour
+	defaultLockTTL = time.Minute
+	defaultMaxBody = 1 << 20
+	maxKeyLength   = 255
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid idempotency key")
+	ErrInProgress = errors.New("request with the same idempotency key is in progress")
+	ErrKeyReused  = errors.New("idempotency key is reused with a different request")
+	ErrLeaseLost  = errors.New("idempotency key lease lost")
+)
+
+const (
+	// если ключ уже есть - возвращается запись, иначе ключ занимается записью "в обработке"
+	beginScript = `
+local v = redis.call("GET", KEYS[1])
+if v then return v end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return ""
+`
+	// ответ сохраняет только владелец записи в обработке
+	completeScript = `
+local v = redis.call("GET", KEYS[1])
+if not v or cjson.decode(v).token ~= ARGV[1] then return 0 end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`
+	// освобождает ключ для повтора, если операция не выполнена
+	releaseScript = `
+local v = redis.call("GET", KEYS[1])
+if not v or cjson.decode(v).token ~= ARGV[1] then return 0 end
+return redis.call("DEL", KEYS[1])
+`
+)
+
+// эмуляция скриптов для redis.Memory в тестах
+func init() {
+	redis.RegisterMemoryScript(beginScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if v, ok := tx.Get(keys[0]); ok {
+			return v, nil
+		}
+		tx.Set(keys[0], args[0], redis.MemoryMillis(args[1]))
+		return "", nil
+	})
+	redis.RegisterMemoryScript(completeScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if !ownedBy(tx, keys[0], args[0]) {
+			return int64(0), nil
+		}
+		tx.Set(keys[0], args[1], redis.MemoryMillis(args[2]))
+		return int64(1), nil
+	})
+	redis.RegisterMemoryScript(releaseScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if !ownedBy(tx, keys[0], args[0]) || !tx.Del(keys[0]) {
+			return int64(0), nil
+		}
+		return int64(1), nil
+	})
+}
+
+// ownedBy запись в обработке с токеном владельца, аналог cjson.decode(v).token == token
+func ownedBy(tx *redis.MemoryTx, key, token string) bool {
+	v, ok := tx.Get(key)
+	if !ok {
+		return false
+	}
+	var rec record
+	return json.Unmarshal([]byte(v), &rec) == nil && rec.Token == token
+}
+
+// Response сохраненный ответ: для HTTP статус, заголовки и тело,
+// для gRPC - сообщение в protobuf и его тип либо код и текст ошибки
+type Response struct {
+	Status  int                 `json:"status,omitempty"`
+	Header  map[string][]string `json:"header,omitempty"`
+	Body    []byte              `json:"body,omitempty"`
+	Type    string              `json:"type,omitempty"`
+	Code    uint32              `json:"code,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+type record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Token       string    `json:"token,omitempty"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// Store записи ключей идемпотентности в redis
+type Store struct {
+	cli     redis.Redis
+	prefix  string
+	ttl     time.Duration
+	lockTTL time.Duration
+	maxBody int
+	scope   func(ctx context.Context) string
+}
+
+type Option func(*Store)
+
+// WithTTL сколько хранится ответ, по умолчанию 24h
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Store) { s.ttl = ttl }
+}
+
+// WithLockTTL сколько ключ считается в обработке, если обработчик не завершился (падение реплики), по умолчанию 1m
+func WithLockTTL(ttl time.Duration) Option {
+	return func(s *Store) { s.lockTTL = ttl }
+}
+
+// WithPrefix префикс ключей redis, по умолчанию "idempotency:"
+func WithPrefix(prefix string) Option {
+	return func(s *Store) { s.prefix = prefix }
+}
+
+// WithMaxBodySize максимальный размер тела запроса и сохраняемого ответа, по умолчанию 1MB
+func WithMaxBodySize(size int) Option {
+	return func(s *Store) { s.maxBody = size }
+}
+
+// WithScope область ключей, например id пользователя, чтобы ключи разных клиентов не пересекались
+func WithScope(fn func(ctx context.Context) string) Option {
+	return func(s *Store) { s.scope = fn }
+}
+
+func NewStore(cli redis.Redis, opts ...Option) *Store {
+	s := &Store{
+		cli:     cli,
+		prefix:  defaultPrefix,
+		ttl:     defaultTTL,
+		lockTTL: defaultLockTTL,
+		maxBody: defaultMaxBody,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Fingerprint отпечаток запроса, тот же ключ с другим отпечатком - ErrKeyReused
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Lease ключ, занятый текущим запросом
+type Lease struct {
+	store *Store
+	key   string
+	token string
+	fp    string
+}
+
+// Begin занимает ключ. Если запрос с этим ключом уже выполнен, возвращается сохраненный ответ,
+// если выполняется - ErrInProgress
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Lease, *Response, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, nil, ErrInvalidKey
+	}
+	if s.scope != nil {
+		key = s.scope(ctx) + ":" + key
+	}
+	key = s.prefix + key
+
+	token := uuid.NewString()
+	pending, err := json.Marshal(record{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := s.cli.Eval(ctx, beginScript, []string{key}, string(pending), s.lockTTL.Milliseconds())
+	if err != nil {
+		return nil, nil, fmt.Errorf("idempotency begin %s: %w", key, err)
+	}
+	existing, _ := res.(string)
+	if existing == "" {
+		return &Lease{store: s, key: key, token: token, fp: fingerprint}, nil, nil
+	}
+
+	var rec record
+	if err := json.Unmarshal([]byte(existing), &rec); err != nil {
+		return nil, nil, fmt.Errorf("idempotency record %s: %w", key, err)
+	}
+	if rec.Fingerprint != fingerprint {
+		return nil, nil, ErrKeyReused
+	}
+	if rec.Response == nil {
+		return nil, nil, ErrInProgress
+	}
+	return nil, rec.Response, nil
+}
+
+// Complete сохраняет ответ на ttl
+func (l *Lease) Complete(ctx context.Context, resp Response) error {
+	done, err := json.Marshal(record{Fingerprint: l.fp, Response: &resp})
+	if err != nil {
+		return err
+	}
+	return l.eval(ctx, completeScript, l.token, string(done), l.store.ttl.Milliseconds())
+}
+
+// Release освобождает ключ без сохранения ответа, повтор запроса выполнит операцию заново
+func (l *Lease) Release(ctx context.Context) error {
+	return l.eval(ctx, releaseScript, l.token)
+}
+
+func (l *Lease) eval(ctx context.Context, script string, args ...any) error {
+	res, err := l.store.cli.Eval(ctx, script, []string{l.key}, args...)
+	if err != nil {
+		return fmt.Errorf("idempotency %s: %w", l.key, err)
+	}
+	if n, _ := res.(int64); n != 1 {
+		return fmt.Errorf("%w: %s", ErrLeaseLost, l.key)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(redis.NewMemory(), WithScope(func(context.Context) string { return "user-1" }))
+
+	lease, stored, err := s.Begin(ctx, "k1", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, _, err = s.Begin(ctx, "k1", "fp")
+	assert.ErrorIs(t, err, ErrInProgress)
+	_, _, err = s.Begin(ctx, "k1", "other")
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	require.NoError(t, lease.Complete(ctx, Response{Status: http.StatusCreated}))
+	assert.ErrorIs(t, lease.Release(ctx), ErrLeaseLost)
+
+	_, stored, err = s.Begin(ctx, "k1", "fp")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, stored.Status)
+
+	lease, _, err = s.Begin(ctx, "k2", "fp")
+	require.NoError(t, err)
+	require.NoError(t, lease.Release(ctx))
+	_, stored, err = s.Begin(ctx, "k2", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, _, err = s.Begin(ctx, strings.Repeat("k", maxKeyLength+1), "fp")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	handler := Middleware(NewStore(redis.NewMemory()))(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if string(body) == "anonymous" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Payment-Id", "p1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+
+	call := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderKey, key)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := call("k1", "pay")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = call("k1", "pay")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "pay", rec.Body.String())
+	assert.Equal(t, "p1", rec.Header().Get("X-Payment-Id"))
+	assert.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, call("k1", "refund").Code)
+
+	// 5xx не сохраняется, повтор выполняется заново
+	assert.Equal(t, http.StatusInternalServerError, call("k2", "fail").Code)
+	assert.Equal(t, http.StatusInternalServerError, call("k2", "fail").Code)
+	assert.Equal(t, 3, calls)
+
+	// 401 не сохраняется, повтор после авторизации выполнит операцию
+	assert.Equal(t, http.StatusUnauthorized, call("k3", "anonymous").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("k3", "anonymous").Code)
+	assert.Equal(t, 5, calls)
+
+	// без ключа мидлварь не применяется
+	call("", "pay")
+	call("", "pay")
+	assert.Equal(t, 7, calls)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(NewStore(redis.NewMemory()))
+	info := &grpc.UnaryServerInfo{FullMethod: "/payments.Payments/Create"}
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		if req.(*wrapperspb.StringValue).Value == "declined" {
+			return nil, status.Error(codes.FailedPrecondition, "insufficient funds")
+		}
+		return wrapperspb.String("payment-" + req.(*wrapperspb.StringValue).Value), nil
+	}
+	ctx := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, key))
+	}
+
+	resp, err := interceptor(ctx("k1"), wrapperspb.String("1"), info, handler)
+	require.NoError(t, err)
+	resp, err = interceptor(ctx("k1"), wrapperspb.String("1"), info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "payment-1", resp.(*wrapperspb.StringValue).Value)
+	assert.Equal(t, 1, calls)
+
+	_, err = interceptor(ctx("k1"), wrapperspb.String("2"), info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// ошибка клиента сохраняется вместе с текстом
+	_, _ = interceptor(ctx("k2"), wrapperspb.String("declined"), info, handler)
+	_, err = interceptor(ctx("k2"), wrapperspb.String("declined"), info, handler)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "insufficient funds", status.Convert(err).Message())
+	assert.Equal(t, 2, calls)
+}
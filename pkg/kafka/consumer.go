@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
@@ -32,6 +34,9 @@ type reader interface {
 type Consumer interface {
 	Init(ctx context.Context, dialer *dialer, brokers []string) error
 	Run(ctx context.Context) error
+	Stop(ctx context.Context) error
+	// Ready возвращает ошибку, пока консумер не вошел в группу
+	Ready() error
 	Close() error
 }
 
@@ -40,6 +45,11 @@ type consumer struct {
 	reader      reader
 	handler     ConsumeHandler
 	middlewares []consumeMiddleware
+
+	stop       chan struct{} // сигнал прекратить чтение новых сообщений
+	stopOnce   sync.Once
+	processing sync.Mutex  // удерживается на время обработки сообщения
+	joined     atomic.Bool // консумер получил партиции группы хотя бы раз
 }
 
 // newConsumer only create consumer with config.
@@ -74,6 +84,7 @@ func newConsumer(
 	c := &consumer{
 		config:  config,
 		handler: handler,
+		stop:    make(chan struct{}),
 	}
 
 	return c, nil
@@ -82,35 +93,68 @@ func newConsumer(
 // Start run topic listener.
 func (c *consumer) Run(ctx context.Context) error {
 	ctx = logger.With(ctx, logger.String("topic", c.config.Topic))
+
+	// чтение прерывается и по ctx, и по Stop, обработка сообщения - только по ctx
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-fetchCtx.Done():
+		}
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-c.stop:
+			return nil
 		default:
-			msg, err := c.reader.FetchMessage(ctx)
+			msg, err := c.reader.FetchMessage(fetchCtx)
 			if err != nil {
+				if fetchCtx.Err() != nil {
+					continue
+				}
 				logger.Error(ctx, "Error fetching message",
 					logger.Err(err),
 				)
 				continue
 			}
 
-			consumeFunc := c.createConsumeChain()
+			c.joined.Store(true)
+			c.consume(ctx, msg)
+		}
+	}
+}
 
-			if err := consumeFunc(ctx, msg); err != nil {
-				logger.Error(ctx, "Error processing message",
-					logger.Any("message", msg),
-					logger.Err(err),
-				)
-				continue
-			}
+// consume обрабатывает и коммитит сообщение,
+// после Stop сообщение не обрабатывается и будет перечитано после ребалансировки
+func (c *consumer) consume(ctx context.Context, msg Message) {
+	c.processing.Lock()
+	defer c.processing.Unlock()
 
-			if err := c.reader.CommitMessages(ctx, msg); err != nil {
-				logger.Error(ctx, "Error committing message",
-					logger.Err(err),
-				)
-			}
-		}
+	select {
+	case <-c.stop:
+		return
+	default:
+	}
+
+	consumeFunc := c.createConsumeChain()
+
+	if err := consumeFunc(ctx, msg); err != nil {
+		logger.Error(ctx, "Error processing message",
+			logger.Any("message", msg),
+			logger.Err(err),
+		)
+		return
+	}
+
+	if err := c.reader.CommitMessages(ctx, msg); err != nil {
+		logger.Error(ctx, "Error committing message",
+			logger.Err(err),
+		)
 	}
 }
 
@@ -149,6 +193,37 @@ func (c *consumer) Init(
 	return nil
 }
 
+// Stop прекращает чтение новых сообщений и ждет завершения обработки текущего
+func (c *consumer) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		c.processing.Lock()
+		defer c.processing.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ready консумер без группы читает партицию напрямую и готов сразу,
+// консумер группы - после первого назначения партиций
+func (c *consumer) Ready() error {
+	if c.config.GroupID == "" || c.joined.Load() {
+		return nil
+	}
+	return fmt.Errorf("consumer of topic %s has not joined group %s yet", c.config.Topic, c.config.GroupID)
+}
+
 // Close finish him
 func (c *consumer) Close() error {
 	if c.reader == nil {
@@ -167,6 +242,10 @@ func (c *consumer) collectLagMetrics(ctx context.Context) {
 			return
 		case <-ticker.C:
 			stats := c.reader.Stats()
+			// Stats сбрасывает счетчики, поэтому вход в группу отслеживается только здесь
+			if stats.Rebalances > 0 {
+				c.joined.Store(true)
+			}
 			// Lag по всем партициям
 			metrics.KafkaConsumerLag.WithLabelValues(c.config.Topic).Set(float64(stats.Lag))
 		}
@@ -223,6 +223,31 @@ func TestConsumer_Start_ImmediateCancel(t *testing.T) {
 	}
 }
 
+func TestConsumer_Ready(t *testing.T) {
+	handler := func(ctx context.Context, msg Message) error { return nil }
+
+	c, err := newConsumer("topic", "group", handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Ready(); err == nil {
+		t.Fatal("expected error before joining group")
+	}
+	c.joined.Store(true)
+	if err := c.Ready(); err != nil {
+		t.Fatalf("unexpected error after joining group: %v", err)
+	}
+
+	// без группы партиция читается напрямую
+	c, err = newConsumer("topic", "", handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Ready(); err != nil {
+		t.Fatalf("unexpected error without group: %v", err)
+	}
+}
+
 func TestConsumer_Close(t *testing.T) {
 	// nil reader
 	c := &consumer{}
@@ -240,3 +265,97 @@ func TestConsumer_Close(t *testing.T) {
 		t.Fatalf("close count = %d, want 1", m.closeCount)
 	}
 }
+
+func TestConsumer_Stop_WaitsInFlight(t *testing.T) {
+	m := &mockReader{
+		fetchMsgs: []Message{{Topic: "t"}},
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished int32
+	cons, err := newConsumer("topic", "group", func(ctx context.Context, msg Message) error {
+		close(started)
+		<-release
+		atomic.AddInt32(&finished, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := cons
+	c.reader = m
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(context.Background())
+	}()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- c.Stop(context.Background())
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before in-flight message was processed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("unexpected stop error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return on time")
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected run error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not exit after Stop")
+	}
+
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Fatalf("finished = %d, want 1", finished)
+	}
+	if atomic.LoadInt32(&m.commitCount) != 1 {
+		t.Fatalf("commit count = %d, want 1", m.commitCount)
+	}
+}
+
+func TestConsumer_Stop_Timeout(t *testing.T) {
+	m := &mockReader{
+		fetchMsgs: []Message{{Topic: "t"}},
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	cons, err := newConsumer("topic", "group", func(ctx context.Context, msg Message) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := cons
+	c.reader = m
+
+	go func() {
+		_ = c.Run(context.Background())
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
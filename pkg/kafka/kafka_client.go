@@ -37,6 +37,8 @@ type kafkaClient struct {
 type KafkaClient interface {
 	Run(context.Context) error
 	HealthCheck(context.Context) error
+	// Ready возвращает ошибку, пока хотя бы один консумер не вошел в группу
+	Ready(context.Context) error
 	RegisterConsumer(
 		ctx context.Context,
 		topic string,
@@ -49,6 +51,7 @@ type KafkaClient interface {
 		opts ...ProducerOption,
 	) (Producer, error)
 	GetProducer(topic string) (Producer, bool)
+	StopConsumers(ctx context.Context) error
 	Close() error
 }
 
@@ -125,6 +128,15 @@ func (k *kafkaClient) HealthCheck(ctx context.Context) error {
 	return k.health.GetState().err
 }
 
+// Ready return error if some consumers have not joined their group yet.
+func (k *kafkaClient) Ready(context.Context) error {
+	var err error
+	for _, consumer := range k.consumers {
+		err = errors.Join(err, consumer.Ready())
+	}
+	return err
+}
+
 // RegisterConsumer add listener with callback handler for topic.
 func (k *kafkaClient) RegisterConsumer(
 	ctx context.Context,
@@ -279,6 +291,30 @@ func (k *kafkaClient) createTopic(ctx context.Context, topic string, config Crea
 	return ctrlConn.CreateTopics(cfg)
 }
 
+// StopConsumers прекращает чтение во всех консумерах и ждет завершения обработки сообщений.
+func (k *kafkaClient) StopConsumers(ctx context.Context) error {
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		err error
+	)
+
+	for _, consumer := range k.consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errS := consumer.Stop(ctx); errS != nil {
+				mu.Lock()
+				err = errors.Join(err, errS)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return err
+}
+
 // Close implements KafkaClient.
 func (k *kafkaClient) Close() error {
 	var err error
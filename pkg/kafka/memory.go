@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+)
+
+// MemoryClient in-memory реализация KafkaClient для тестов.
+// Отправленные сообщения сохраняются и синхронно передаются консумерам топика после Run
+type MemoryClient struct {
+	mu        sync.RWMutex
+	handlers  map[string][]ConsumeHandler
+	producers map[string]Producer
+	messages  map[string][]Message
+	running   bool
+}
+
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		handlers:  make(map[string][]ConsumeHandler),
+		producers: make(map[string]Producer),
+		messages:  make(map[string][]Message),
+	}
+}
+
+func (m *MemoryClient) Run(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = true
+	return nil
+}
+
+func (m *MemoryClient) HealthCheck(context.Context) error {
+	return nil
+}
+
+// Ready консумеры считаются вошедшими в группу после Run
+func (m *MemoryClient) Ready(context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.running {
+		return errors.New("in-memory kafka is not running")
+	}
+	return nil
+}
+
+func (m *MemoryClient) RegisterConsumer(_ context.Context, topic string, handler ConsumeHandler, _ ...ConsumeOption) error {
+	if handler == nil {
+		return errors.New("handler not defined")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[topic] = append(m.handlers[topic], handler)
+	return nil
+}
+
+func (m *MemoryClient) RegisterProducer(_ context.Context, topic string, _ ...ProducerOption) (Producer, error) {
+	if len(topic) == 0 {
+		return nil, errors.New("topic not specified")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if producer, ok := m.producers[topic]; ok {
+		return producer, nil
+	}
+	producer := &memoryProducer{client: m, topic: topic}
+	m.producers[topic] = producer
+	return producer, nil
+}
+
+func (m *MemoryClient) GetProducer(topic string) (Producer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	producer, ok := m.producers[topic]
+	return producer, ok
+}
+
+func (m *MemoryClient) StopConsumers(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = false
+	return nil
+}
+
+func (m *MemoryClient) Close() error {
+	return nil
+}
+
+// Messages возвращает сообщения, отправленные в топик
+func (m *MemoryClient) Messages(topic string) []Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Message(nil), m.messages[topic]...)
+}
+
+// Deliver передает сообщение консумерам топика и возвращает ошибки обработчиков,
+// используется для проверки консумеров без продюсера
+func (m *MemoryClient) Deliver(ctx context.Context, msg Message) error {
+	m.mu.RLock()
+	handlers := m.handlers[msg.Topic]
+	m.mu.RUnlock()
+
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	var err error
+	for _, handler := range handlers {
+		err = errors.Join(err, handler(ctx, msg))
+	}
+	return err
+}
+
+type memoryProducer struct {
+	client *MemoryClient
+	topic  string
+}
+
+func (p *memoryProducer) Produce(ctx context.Context, messages ...ProduceMessage) error {
+	for _, m := range messages {
+		msg := Message{
+			Topic: p.topic,
+			Key:   m.Key,
+			Value: m.Value,
+			Time:  time.Now(),
+		}
+
+		p.client.mu.Lock()
+		p.client.messages[p.topic] = append(p.client.messages[p.topic], msg)
+		running := p.client.running
+		p.client.mu.Unlock()
+
+		if !running {
+			continue
+		}
+		// как и в kafka, ошибка консумера не возвращается продюсеру
+		if err := p.client.Deliver(ctx, msg); err != nil {
+			logger.Error(ctx, "in-memory kafka consumer failed", logger.String("topic", p.topic), logger.Err(err))
+		}
+	}
+	return nil
+}
+
+func (p *memoryProducer) Close() error {
+	return nil
+}
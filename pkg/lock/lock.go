@@ -0,0 +1,28 @@
+// lock распределенные блокировки поверх redis или postgres
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotAcquired = errors.New("lock not acquired")
+	ErrLockLost    = errors.New("lock lost")
+)
+
+// Locker захватывает блокировки по ключу
+type Locker interface {
+	// TryLock пытается захватить блокировку без ожидания,
+	// если блокировка занята другим владельцем - возвращает ErrNotAcquired
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock захваченная блокировка
+type Lock interface {
+	// Refresh продлевает блокировку на ttl, если блокировка потеряна - возвращает ErrLockLost
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// Unlock освобождает блокировку, если она еще принадлежит владельцу
+	Unlock(ctx context.Context) error
+}
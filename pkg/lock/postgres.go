@@ -0,0 +1,85 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/db"
+)
+
+type postgresLocker struct {
+	cli db.DbClient
+}
+
+// NewPostgres блокировки на pg_try_advisory_lock.
+// Блокировка сессионная: держит отдельное соединение из пула до Unlock,
+// ttl не используется, при обрыве соединения блокировка освобождается
+func NewPostgres(cli db.DbClient) Locker {
+	return &postgresLocker{cli: cli}
+}
+
+func (l *postgresLocker) TryLock(ctx context.Context, key string, _ time.Duration) (Lock, error) {
+	sqlDB, err := l.cli.DB(ctx).DB()
+	if err != nil {
+		return nil, fmt.Errorf("postgres lock %s: %w", key, err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgres lock %s: %w", key, err)
+	}
+
+	id := advisoryKey(key)
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&ok); err != nil {
+		// запрос мог выполниться и захватить блокировку, например при отмене ctx
+		discard(conn)
+		return nil, fmt.Errorf("postgres lock %s: %w", key, err)
+	}
+	if !ok {
+		// блокировка не захвачена, соединение можно вернуть в пул
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotAcquired, key)
+	}
+	return &postgresLock{conn: conn, key: key, id: id}, nil
+}
+
+// advisoryKey переводит строковый ключ в bigint для advisory lock
+func advisoryKey(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+type postgresLock struct {
+	conn *sql.Conn
+	key  string
+	id   int64
+}
+
+// Refresh проверяет что соединение, на котором держится блокировка, живо
+func (l *postgresLock) Refresh(ctx context.Context, _ time.Duration) error {
+	if err := l.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrLockLost, l.key, err)
+	}
+	return nil
+}
+
+func (l *postgresLock) Unlock(ctx context.Context) error {
+	var ok bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.id).Scan(&ok); err != nil {
+		discard(l.conn)
+		return fmt.Errorf("postgres unlock %s: %w", l.key, err)
+	}
+	return l.conn.Close()
+}
+
+// discard закрывает соединение, не возвращая его в пул: сессия с неснятой блокировкой
+// держала бы ее до пересоздания соединения и отдавала бы повторно любому запросу из пула
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
@@ -0,0 +1,38 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenConn соединение, на котором любой запрос падает, например после таймаута
+type brokenConn struct{}
+
+func (brokenConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("connection reset") }
+func (brokenConn) Close() error                        { return nil }
+func (brokenConn) Begin() (driver.Tx, error)           { return nil, errors.New("connection reset") }
+
+type brokenConnector struct{}
+
+func (brokenConnector) Connect(context.Context) (driver.Conn, error) { return brokenConn{}, nil }
+func (brokenConnector) Driver() driver.Driver                        { return nil }
+
+func TestPostgresUnlockDiscardsConn(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := sql.OpenDB(brokenConnector{})
+	defer sqlDB.Close()
+
+	conn, err := sqlDB.Conn(ctx)
+	require.NoError(t, err)
+	l := &postgresLock{conn: conn, key: "reports", id: advisoryKey("reports")}
+
+	// блокировка не снята, соединение с ней не возвращается в пул
+	assert.Error(t, l.Unlock(ctx))
+	assert.Zero(t, sqlDB.Stats().OpenConnections)
+}
@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// SET NX PX, блокировка захватывается только если ключ свободен
+	lockScript = `if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return 1 end return 0`
+	// продление только своей блокировки
+	refreshScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`
+	// удаление только своей блокировки
+	unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+)
+
+// эмуляция скриптов для redis.Memory в тестах
+func init() {
+	redis.RegisterMemoryScript(lockScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if _, ok := tx.Get(keys[0]); ok {
+			return int64(0), nil
+		}
+		tx.Set(keys[0], args[0], redis.MemoryMillis(args[1]))
+		return int64(1), nil
+	})
+	redis.RegisterMemoryScript(refreshScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if v, ok := tx.Get(keys[0]); ok && v == args[0] && tx.Expire(keys[0], redis.MemoryMillis(args[1])) {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	})
+	redis.RegisterMemoryScript(unlockScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		if v, ok := tx.Get(keys[0]); ok && v == args[0] && tx.Del(keys[0]) {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	})
+}
+
+type redisLocker struct {
+	cli redis.Redis
+}
+
+// NewRedis блокировки на ключах redis с ttl, ключ хранит токен владельца
+func NewRedis(cli redis.Redis) Locker {
+	return &redisLocker{cli: cli}
+}
+
+func (l *redisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token := uuid.NewString()
+	ok, err := l.eval(ctx, lockScript, key, token, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("redis lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotAcquired, key)
+	}
+	return &redisLock{locker: l, key: key, token: token}, nil
+}
+
+func (l *redisLocker) eval(ctx context.Context, script, key, token string, ttl time.Duration) (bool, error) {
+	res, err := l.cli.Eval(ctx, script, []string{key}, token, ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.(int64)
+	return n == 1, nil
+}
+
+type redisLock struct {
+	locker *redisLocker
+	key    string
+	token  string
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	ok, err := l.locker.eval(ctx, refreshScript, l.key, l.token, ttl)
+	if err != nil {
+		return fmt.Errorf("redis lock refresh %s: %w", l.key, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLockLost, l.key)
+	}
+	return nil
+}
+
+func (l *redisLock) Unlock(ctx context.Context) error {
+	if _, err := l.locker.eval(ctx, unlockScript, l.key, l.token, 0); err != nil {
+		return fmt.Errorf("redis unlock %s: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLock(t *testing.T) {
+	ctx := context.Background()
+	locker := NewRedis(redis.NewMemory())
+
+	l, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	_, err = locker.TryLock(ctx, "job", time.Minute)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	require.NoError(t, l.Refresh(ctx, time.Minute))
+	require.NoError(t, l.Unlock(ctx))
+	assert.ErrorIs(t, l.Refresh(ctx, time.Minute), ErrLockLost)
+
+	_, err = locker.TryLock(ctx, "job", time.Minute)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	AuthRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_requests_total",
+			Help: "Total number of requests checked by authentication",
+		},
+		[]string{"transport", "result"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(AuthRequestsTotal)
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"git.vepay.dev/knoknok/backend-platform/pkg/buildinfo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	BuildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "build_info",
+			Help: "Build information, value is always 1",
+		},
+		[]string{"version", "commit", "go_version"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		BuildInfo,
+	)
+
+	info := buildinfo.Get()
+	BuildInfo.WithLabelValues(info.Version, info.Commit, info.GoVersion).Set(1)
+}
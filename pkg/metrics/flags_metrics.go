@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	FeatureFlagEvaluationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "feature_flag_evaluations_total",
+			Help: "Total number of feature flag evaluations",
+		},
+		[]string{"flag", "result"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(FeatureFlagEvaluationsTotal)
+}
@@ -23,6 +23,22 @@ var (
 		[]string{"method"},
 	)
 
+	GrpcClientUpstreamUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_client_upstream_up",
+			Help: "1 if last active health check of gRPC upstream succeeded, 0 otherwise",
+		},
+		[]string{"service"},
+	)
+
+	GrpcClientHealthChecksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_client_health_checks_total",
+			Help: "Total number of active gRPC upstream health checks",
+		},
+		[]string{"service", "result"},
+	)
+
 	GrpcServerRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "grpc_server_requests_total",
@@ -45,6 +61,8 @@ func init() {
 	Registry.MustRegister(
 		GrpcClientRequestDuration,
 		GrpcClientRequestsTotal,
+		GrpcClientUpstreamUp,
+		GrpcClientHealthChecksTotal,
 		GrpcServerRequestsTotal,
 		GrpcServerRequestDuration,
 	)
@@ -0,0 +1,38 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	HealthCheckUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "health_check_up",
+			Help: "1 if health check is up, 0 if down after failure threshold",
+		},
+		[]string{"probe", "check"},
+	)
+
+	HealthCheckTransitionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "health_check_transitions_total",
+			Help: "Total number of health check state changes",
+		},
+		[]string{"probe", "check", "status"},
+	)
+
+	HealthCheckDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "health_check_duration_seconds",
+			Help:    "Health check execution duration",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"probe", "check"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		HealthCheckUp,
+		HealthCheckTransitionsTotal,
+		HealthCheckDuration,
+	)
+}
@@ -19,10 +19,19 @@ var (
 		},
 		[]string{"method", "path", "status_code"},
 	)
+
+	HTTPPanicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_panics_total",
+			Help: "Total number of panics recovered in HTTP handlers",
+		},
+		[]string{"method", "path"},
+	)
 )
 
 func init() {
 	Registry.MustRegister(
 		HTTPRequestsTotal,
-		HTTPRequestDurationSeconds)
+		HTTPRequestDurationSeconds,
+		HTTPPanicsTotal)
 }
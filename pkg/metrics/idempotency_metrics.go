@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	IdempotencyRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "idempotency_requests_total",
+			Help: "Total number of requests with idempotency key",
+		},
+		[]string{"result"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(IdempotencyRequestsTotal)
+}
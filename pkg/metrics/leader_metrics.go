@@ -0,0 +1,19 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	LeaderElectionIsLeader = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "leader_election_is_leader",
+			Help: "1 if this replica holds leadership, 0 otherwise",
+		},
+		[]string{"key"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		LeaderElectionIsLeader,
+	)
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	RateLimitRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ratelimit_requests_total",
+			Help: "Total number of requests checked by rate limit rules",
+		},
+		[]string{"rule", "result"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(RateLimitRequestsTotal)
+}
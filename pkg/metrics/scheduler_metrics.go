@@ -0,0 +1,38 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	ScheduledJobRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduled_job_runs_total",
+			Help: "Total number of scheduled job runs",
+		},
+		[]string{"job", "status"}, // status: success | error | skipped
+	)
+
+	ScheduledJobDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scheduled_job_duration_seconds",
+			Help:    "Scheduled job run duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"job"},
+	)
+
+	ScheduledJobLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scheduled_job_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful scheduled job run",
+		},
+		[]string{"job"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		ScheduledJobRunsTotal,
+		ScheduledJobDurationSeconds,
+		ScheduledJobLastSuccess,
+	)
+}
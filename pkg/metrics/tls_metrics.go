@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	TLSCertificateExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tls_certificate_expiry_timestamp_seconds",
+			Help: "NotAfter of the currently served TLS certificate",
+		},
+		[]string{"name"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(TLSCertificateExpiry)
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware мидлварь HTTP: заголовки RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset,
+// при превышении 429 Too Many Requests и Retry-After
+func (l *Limiter) Middleware() func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			res, checked := l.Check(r.Context(), &Request{
+				Method: r.Method,
+				Path:   r.URL.Path,
+				IP:     clientIP(r),
+				Header: r.Header.Get,
+			})
+			if checked {
+				setHeaders(w.Header().Set, res)
+			}
+			if !res.Allowed {
+				w.Header().Set("Retry-After", seconds(res.RetryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+func setHeaders(set func(key, value string), res Result) {
+	set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	set("RateLimit-Reset", seconds(res.Reset))
+}
+
+// seconds округление вверх, чтобы клиент не повторял запрос раньше времени
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// clientIP адрес соединения, X-Forwarded-For не учитывается: его может подставить сам клиент.
+// За балансировщиком используйте ByHeader с заголовком, который выставляет балансировщик
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
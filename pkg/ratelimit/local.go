@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const localSweepInterval = time.Minute
+
+// Local token bucket в памяти процесса: емкость Burst, пополнение Rate токенов за Period
+type Local struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	idle   time.Duration // через сколько полный бакет можно удалить
+}
+
+func NewLocal() *Local {
+	return &Local{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *Local) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if !limit.valid() {
+		return Result{}, ErrInvalidLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(limit.burst())
+	perToken := limit.Period / time.Duration(limit.Rate)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+	b.idle = time.Duration(capacity) * perToken
+
+	res := Result{Limit: limit.burst()}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+	return res, nil
+}
+
+// sweep удаляет бакеты, которые успели заполниться, чтобы map не росла от разовых ключей
+func (l *Local) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < localSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > b.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package ratelimit ограничение частоты запросов: локальный token bucket и распределенное
+// скользящее окно в redis, мидлварь для HTTP и интерсепторы для gRPC
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+)
+
+var ErrInvalidLimit = errors.New("rate limit must be positive")
+
+// Limit допустимое число запросов Rate за Period.
+// Burst - емкость token bucket, по умолчанию Rate, для скользящего окна не используется
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+func (l Limit) valid() bool {
+	return l.Rate > 0 && l.Period > 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result решение по запросу
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // через сколько лимит восстановится полностью
+	RetryAfter time.Duration // через сколько можно повторить отклоненный запрос
+}
+
+// Backend хранилище счетчиков
+type Backend interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Request запрос HTTP или gRPC в виде, общем для правил и ключей
+type Request struct {
+	Method string // HTTP метод или полное имя gRPC метода (/pkg.Service/Method)
+	Path   string // HTTP путь или полное имя gRPC метода
+	IP     string
+	Header func(name string) string // HTTP заголовок или gRPC metadata
+}
+
+// KeyFunc ключ, по которому считается лимит. Пустой ключ - правило к запросу не применяется
+type KeyFunc func(ctx context.Context, r *Request) string
+
+// ByIP адрес клиента
+func ByIP() KeyFunc {
+	return func(_ context.Context, r *Request) string { return r.IP }
+}
+
+// ByHeader значение заголовка или metadata, например X-Api-Key
+func ByHeader(name string) KeyFunc {
+	return func(_ context.Context, r *Request) string { return r.Header(name) }
+}
+
+// ByMethod общий лимит на метод (путь) для всех клиентов
+func ByMethod() KeyFunc {
+	return func(_ context.Context, r *Request) string { return r.Path }
+}
+
+// ByContext значение из контекста, например id пользователя после аутентификации
+func ByContext(fn func(ctx context.Context) string) KeyFunc {
+	return func(ctx context.Context, _ *Request) string { return fn(ctx) }
+}
+
+// Rule правило ограничения.
+// Limit - значение по умолчанию, во время работы переопределяется через Limits (конфиг в consul)
+type Rule struct {
+	Name        string
+	Match       func(r *Request) bool // nil - все запросы
+	Key         KeyFunc
+	Limit       Limit
+	Distributed bool // скользящее окно в redis, общее для всех реплик, иначе token bucket в памяти реплики
+}
+
+// PathPrefix правило применяется к путям (gRPC методам) с префиксом
+func PathPrefix(prefixes ...string) func(r *Request) bool {
+	return func(r *Request) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LimitsFunc текущий лимит правила, false - правило отключено
+type LimitsFunc func(rule Rule) (Limit, bool)
+
+// Limiter проверяет запрос по всем подходящим правилам
+type Limiter struct {
+	rules    []Rule
+	local    Backend
+	remote   Backend
+	limits   LimitsFunc
+	failOpen bool
+}
+
+type Option func(*Limiter)
+
+// WithBackend хранилище для правил Distributed, обычно NewRedis
+func WithBackend(b Backend) Option {
+	return func(l *Limiter) { l.remote = b }
+}
+
+// WithLimits источник лимитов во время работы, по умолчанию Rule.Limit
+func WithLimits(fn LimitsFunc) Option {
+	return func(l *Limiter) { l.limits = fn }
+}
+
+// WithFailClosed отклонять запросы при ошибке хранилища, по умолчанию запрос пропускается
+func WithFailClosed() Option {
+	return func(l *Limiter) { l.failOpen = false }
+}
+
+func New(rules []Rule, opts ...Option) *Limiter {
+	l := &Limiter{
+		rules:    rules,
+		local:    NewLocal(),
+		failOpen: true,
+		limits: func(rule Rule) (Limit, bool) {
+			return rule.Limit, true
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Check возвращает отказ первого сработавшего правила,
+// при успехе - результат правила с наименьшим остатком для заголовков
+func (l *Limiter) Check(ctx context.Context, r *Request) (Result, bool) {
+	var (
+		res     Result
+		checked bool
+	)
+	for _, rule := range l.rules {
+		if rule.Match != nil && !rule.Match(r) {
+			continue
+		}
+		key := rule.Key(ctx, r)
+		if key == "" {
+			continue
+		}
+		limit, enabled := l.limits(rule)
+		if !enabled || !limit.valid() {
+			continue
+		}
+
+		backend := l.local
+		if rule.Distributed && l.remote != nil {
+			backend = l.remote
+		}
+
+		ruleRes, err := backend.Allow(ctx, rule.Name+":"+key, limit)
+		if err != nil {
+			metrics.RateLimitRequestsTotal.WithLabelValues(rule.Name, "error").Inc()
+			logger.Error(ctx, "Rate limit check failed", logger.String("rule", rule.Name), logger.Err(err))
+			if l.failOpen {
+				continue
+			}
+			return Result{Limit: limit.Rate, RetryAfter: time.Second}, true
+		}
+
+		if !ruleRes.Allowed {
+			metrics.RateLimitRequestsTotal.WithLabelValues(rule.Name, "limited").Inc()
+			logger.Warn(ctx, "Rate limit exceeded", logger.String("rule", rule.Name), logger.String("key", key))
+			return ruleRes, true
+		}
+		metrics.RateLimitRequestsTotal.WithLabelValues(rule.Name, "allowed").Inc()
+
+		if !checked || ruleRes.Remaining < res.Remaining {
+			res, checked = ruleRes, true
+		}
+	}
+	res.Allowed = true
+	return res, checked
+}
@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingRedis redis, недоступный для скриптов
+type failingRedis struct {
+	redis.Redis
+	err error
+}
+
+func (f *failingRedis) Eval(context.Context, string, []string, ...any) (any, error) {
+	return nil, f.err
+}
+
+func TestLocal(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLocal()
+	l.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Period: time.Second}
+
+	for i := 0; i < 2; i++ {
+		res, err := l.Allow(context.Background(), "k", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+	res, _ := l.Allow(context.Background(), "k", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// другой ключ считается отдельно
+	res, _ = l.Allow(context.Background(), "other", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = l.Allow(context.Background(), "k", limit)
+	assert.True(t, res.Allowed)
+
+	// заполненные бакеты удаляются
+	now = now.Add(time.Hour)
+	_, _ = l.Allow(context.Background(), "k", limit)
+	assert.Len(t, l.buckets, 1)
+
+	_, err := l.Allow(context.Background(), "k", Limit{})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestRedis(t *testing.T) {
+	cli := redis.NewMemory()
+	r := NewRedis(cli, "")
+	now := time.UnixMilli(60_000)
+	r.now = func() time.Time { return now }
+	limit := Limit{Rate: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		res, err := r.Allow(context.Background(), "login:1.2.3.4", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2-i, res.Remaining)
+	}
+	res, err := r.Allow(context.Background(), "login:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Minute, res.RetryAfter)
+	assert.NoError(t, cli.Get(context.Background(), "ratelimit:{login:1.2.3.4}:1").Err())
+
+	// в середине следующего окна половина запросов предыдущего еще учитывается
+	now = now.Add(90 * time.Second)
+	res, _ = r.Allow(context.Background(), "login:1.2.3.4", limit)
+	assert.True(t, res.Allowed)
+	res, _ = r.Allow(context.Background(), "login:1.2.3.4", limit)
+	assert.True(t, res.Allowed)
+	res, _ = r.Allow(context.Background(), "login:1.2.3.4", limit)
+	assert.False(t, res.Allowed)
+}
+
+func TestLimiterFailOpen(t *testing.T) {
+	cli := &failingRedis{err: errors.New("connection refused")}
+	rules := []Rule{{Name: "api", Key: ByIP(), Limit: Limit{Rate: 1, Period: time.Minute}, Distributed: true}}
+	req := &Request{IP: "1.2.3.4"}
+
+	res, _ := New(rules, WithBackend(NewRedis(cli, ""))).Check(context.Background(), req)
+	assert.True(t, res.Allowed)
+
+	res, _ = New(rules, WithBackend(NewRedis(cli, "")), WithFailClosed()).Check(context.Background(), req)
+	assert.False(t, res.Allowed)
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := New([]Rule{{
+		Name:  "login",
+		Match: PathPrefix("/login"),
+		Key:   ByHeader("X-Api-Key"),
+		Limit: Limit{Rate: 1, Period: time.Minute},
+	}})
+	handler := limiter.Middleware()(func(w http.ResponseWriter, r *http.Request) {})
+
+	call := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("X-Api-Key", key)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := call("/login", "a")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	rec = call("/login", "a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, call("/login", "b").Code)
+	// без ключа и вне префикса правило не применяется
+	assert.Equal(t, http.StatusOK, call("/login", "").Code)
+	rec = call("/profile", "a")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limiter := New([]Rule{{Name: "method", Key: ByMethod(), Limit: Limit{Rate: 1, Period: time.Minute}}})
+	interceptor := limiter.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
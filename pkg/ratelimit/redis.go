@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/redis"
+)
+
+const defaultRedisPrefix = "ratelimit:"
+
+// slidingWindowScript счетчик текущего окна и взвешенный счетчик предыдущего,
+// KEYS[1] - текущее окно, KEYS[2] - предыдущее, ARGV: лимит, вес предыдущего окна, ttl в мс
+const slidingWindowScript = `
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local count = math.floor(previous * tonumber(ARGV[2])) + current
+if count >= tonumber(ARGV[1]) then
+	return {0, count}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, count + 1}
+`
+
+// эмуляция скрипта для redis.Memory в тестах
+func init() {
+	redis.RegisterMemoryScript(slidingWindowScript, func(tx *redis.MemoryTx, keys, args []string) (any, error) {
+		counter := func(key string) int64 {
+			v, _ := tx.Get(key)
+			n, _ := strconv.ParseInt(v, 10, 64)
+			return n
+		}
+		current := counter(keys[0])
+		limit, _ := strconv.ParseInt(args[0], 10, 64)
+		weight, _ := strconv.ParseFloat(args[1], 64)
+		count := int64(math.Floor(float64(counter(keys[1]))*weight)) + current
+		if count >= limit {
+			return []any{int64(0), count}, nil
+		}
+		tx.Set(keys[0], strconv.FormatInt(current+1, 10), redis.MemoryMillis(args[2]))
+		return []any{int64(1), count + 1}, nil
+	})
+}
+
+// Redis скользящее окно в redis, одинаковое для всех реплик.
+// Окно аппроксимируется двумя фиксированными: запросы предыдущего окна учитываются пропорционально
+// оставшейся части, поэтому на ключ хранится два счетчика
+type Redis struct {
+	client redis.Redis
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedis prefix ключей по умолчанию "ratelimit:"
+func NewRedis(client redis.Redis, prefix string) *Redis {
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	return &Redis{client: client, prefix: prefix, now: time.Now}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.valid() {
+		return Result{}, ErrInvalidLimit
+	}
+
+	now := r.now()
+	period := limit.Period.Milliseconds()
+	if period <= 0 {
+		period = 1
+	}
+	window := now.UnixMilli() / period
+	elapsed := now.UnixMilli() % period
+	weight := float64(period-elapsed) / float64(period)
+
+	// hash tag, чтобы оба счетчика были в одном слоте кластера
+	base := r.prefix + "{" + key + "}:"
+	keys := []string{base + strconv.FormatInt(window, 10), base + strconv.FormatInt(window-1, 10)}
+
+	res, err := r.client.Eval(ctx, slidingWindowScript, keys,
+		limit.Rate, strconv.FormatFloat(weight, 'f', 6, 64), 2*period)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+
+	reset := time.Duration(period-elapsed) * time.Millisecond
+	result := Result{
+		Allowed:   allowed == 1,
+		Limit:     limit.Rate,
+		Remaining: max(limit.Rate-int(count), 0),
+		Reset:     reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	return result, nil
+}
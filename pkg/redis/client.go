@@ -20,6 +20,9 @@ type Redis interface {
 	Get(ctx context.Context, key string) Value
 	Del(ctx context.Context, keys ...string) error
 
+	//lua скрипты, используется для атомарных операций (блокировки и т.п.)
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
 	//для pubsub
 	Publish(ctx context.Context, channel string, msg any) error
 	Subscribe(ctx context.Context, channels ...string) (Subscriber, error)
@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrEvalUnsupported = errors.New("eval is not supported by in-memory redis")
+
+// EvalFunc обработчик lua скриптов для in-memory клиента
+type EvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// MemoryScript эмуляция lua скрипта для Memory. Выполняется под блокировкой клиента, поэтому атомарна,
+// ARGV передаются строками, как в redis, результат должен иметь те же типы, что вернул бы redis
+type MemoryScript func(tx *MemoryTx, keys []string, args []string) (any, error)
+
+var memoryScripts sync.Map
+
+// RegisterMemoryScript регистрирует эмуляцию скрипта для всех Memory клиентов,
+// пакеты платформы со скриптами регистрируют их в init
+func RegisterMemoryScript(script string, fn MemoryScript) {
+	memoryScripts.Store(script, fn)
+}
+
+// Memory in-memory реализация Redis для тестов: ключи с ttl и pub/sub внутри процесса.
+// Lua скрипты не исполняются: Eval вызывает EvalFunc, если он задан, иначе эмуляцию из RegisterMemoryScript
+type Memory struct {
+	EvalFunc EvalFunc
+
+	codec codec
+
+	mu   sync.Mutex
+	keys map[string]memoryItem
+	subs map[*memorySubscriber]struct{}
+}
+
+type memoryItem struct {
+	data      string
+	expiresAt time.Time // нулевое значение - без ttl
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		codec: JSONCodec{},
+		keys:  make(map[string]memoryItem),
+		subs:  make(map[*memorySubscriber]struct{}),
+	}
+}
+
+func (m *Memory) Set(_ context.Context, key string, val any, ttl time.Duration) error {
+	bt, err := m.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	item := memoryItem{data: string(bt)}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[key] = item
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, key string) Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.keys[key]
+	if !ok || item.expired() {
+		delete(m.keys, key)
+		return Value{"", ErrNotFound}
+	}
+	return Value{item.data, nil}
+}
+
+func (m *Memory) Del(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.keys, key)
+	}
+	return nil
+}
+
+func (m *Memory) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	if m.EvalFunc != nil {
+		return m.EvalFunc(ctx, script, keys, args...)
+	}
+	fn, ok := memoryScripts.Load(script)
+	if !ok {
+		return nil, ErrEvalUnsupported
+	}
+
+	argv := make([]string, 0, len(args))
+	for _, arg := range args {
+		argv = append(argv, fmt.Sprint(arg))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn.(MemoryScript)(&MemoryTx{memory: m}, keys, argv)
+}
+
+// Publish доставляет сообщение подписчикам синхронно, при переполненном буфере подписчика сообщение теряется
+func (m *Memory) Publish(_ context.Context, channel string, msg any) error {
+	b, err := m.codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for sub := range m.subs {
+		if !slices.Contains(sub.channels, channel) {
+			continue
+		}
+		select {
+		case sub.ch <- &Message{Channel: channel, Payload: string(b)}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(_ context.Context, channels ...string) (Subscriber, error) {
+	sub := &memorySubscriber{
+		memory:   m,
+		channels: channels,
+		ch:       make(chan *Message, buffchan),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (m *Memory) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (i memoryItem) expired() bool {
+	return !i.expiresAt.IsZero() && time.Now().After(i.expiresAt)
+}
+
+// MemoryMillis ttl из аргумента скрипта в миллисекундах, как у PX и PEXPIRE
+func MemoryMillis(arg string) time.Duration {
+	ms, _ := strconv.ParseInt(arg, 10, 64)
+	return time.Duration(ms) * time.Millisecond
+}
+
+// MemoryTx команды над ключами Memory внутри эмуляции скрипта
+type MemoryTx struct {
+	memory *Memory
+}
+
+// Get значение ключа без декодирования, как GET
+func (tx *MemoryTx) Get(key string) (string, bool) {
+	item, ok := tx.memory.keys[key]
+	if !ok || item.expired() {
+		delete(tx.memory.keys, key)
+		return "", false
+	}
+	return item.data, true
+}
+
+// Set записывает строку без кодека, как SET PX, ttl 0 - без ttl
+func (tx *MemoryTx) Set(key, value string, ttl time.Duration) {
+	item := memoryItem{data: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	tx.memory.keys[key] = item
+}
+
+// Expire меняет ttl существующего ключа, как PEXPIRE
+func (tx *MemoryTx) Expire(key string, ttl time.Duration) bool {
+	item, ok := tx.memory.keys[key]
+	if !ok || item.expired() {
+		return false
+	}
+	item.expiresAt = time.Now().Add(ttl)
+	tx.memory.keys[key] = item
+	return true
+}
+
+// Del удаляет ключ, как DEL
+func (tx *MemoryTx) Del(key string) bool {
+	_, ok := tx.Get(key)
+	delete(tx.memory.keys, key)
+	return ok
+}
+
+type memorySubscriber struct {
+	memory   *Memory
+	channels []string
+	ch       chan *Message
+}
+
+func (s *memorySubscriber) Channel() <-chan *Message {
+	return s.ch
+}
+
+func (s *memorySubscriber) Close() error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	if _, ok := s.memory.subs[s]; ok {
+		delete(s.memory.subs, s)
+		close(s.ch)
+	}
+	return nil
+}
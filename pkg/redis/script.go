@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/logger"
+	"git.vepay.dev/knoknok/backend-platform/pkg/metrics"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+// Eval выполняет lua скрипт через EVALSHA, при отсутствии скрипта в кэше redis - через EVAL.
+// Если скрипт вернул nil, возвращается ErrNotFound
+func (c *client) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+
+	start := time.Now()
+	res, err := goRedis.NewScript(script).Run(ctx, c.universal(), keys, args...).Result()
+	metrics.RedisQueryDuration.WithLabelValues("EVAL").Observe(time.Since(start).Seconds())
+
+	if errors.Is(err, goRedis.Nil) {
+		c.touchActivity()
+		return nil, ErrNotFound
+	}
+
+	if err == nil {
+		c.touchActivity()
+		return res, nil
+	}
+
+	logger.Error(ctx, "redis eval failed",
+		logger.Any("keys", keys),
+		logger.String("error", err.Error()),
+	)
+
+	return nil, fmt.Errorf("redis eval keys=%q: %w", keys, err)
+}
@@ -0,0 +1,129 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// MemoryClient in-memory реализация Client для тестов, объекты хранятся в памяти процесса
+type MemoryClient struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+	metadata    map[string]string
+}
+
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		objects: make(map[string]memoryObject),
+	}
+}
+
+func (m *MemoryClient) Upload(_ context.Context, input *UploadInput) error {
+	if input.Key == "" {
+		return ErrKeyRequired
+	}
+
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[input.Key] = memoryObject{
+		data:        data,
+		contentType: input.ContentType,
+		metadata:    maps.Clone(input.Metadata),
+	}
+	return nil
+}
+
+func (m *MemoryClient) Download(_ context.Context, key string) (*DownloadOutput, error) {
+	if key == "" {
+		return nil, ErrKeyRequired
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, ErrObjectNotFound)
+	}
+
+	return &DownloadOutput{
+		Body:        io.NopCloser(bytes.NewReader(obj.data)),
+		ContentType: obj.contentType,
+		Size:        int64(len(obj.data)),
+		Metadata:    maps.Clone(obj.metadata),
+	}, nil
+}
+
+func (m *MemoryClient) Exist(_ context.Context, key string) (bool, error) {
+	if key == "" {
+		return false, ErrKeyRequired
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.objects[key]
+	return ok, nil
+}
+
+func (m *MemoryClient) Delete(_ context.Context, key string) error {
+	if key == "" {
+		return ErrKeyRequired
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *MemoryClient) Move(_ context.Context, sourceKey, destinationKey string) error {
+	if sourceKey == "" || destinationKey == "" {
+		return ErrKeyRequired
+	}
+	if sourceKey == destinationKey {
+		return ErrSameKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[sourceKey]
+	if !ok {
+		return fmt.Errorf("failed to copy object %s: %w", sourceKey, ErrObjectNotFound)
+	}
+	m.objects[destinationKey] = obj
+	delete(m.objects, sourceKey)
+	return nil
+}
+
+// PresignedGetObject возвращает фиктивную ссылку memory://<key>
+func (m *MemoryClient) PresignedGetObject(_ context.Context, key string, _ time.Duration) (string, error) {
+	if key == "" {
+		return "", ErrKeyRequired
+	}
+	return "memory://" + key, nil
+}
+
+func (m *MemoryClient) Ping(context.Context) error {
+	return nil
+}
+
+func (m *MemoryClient) Close() error {
+	return nil
+}
@@ -0,0 +1,203 @@
+// schedule разбор расписаний в cron формате и интервалов
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule расписание запусков
+type Schedule interface {
+	// Next возвращает время следующего запуска строго после t,
+	// нулевое время если расписание больше не сработает
+	Next(t time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse разбирает расписание:
+//   - cron из 5 полей "минута час день_месяца месяц день_недели", поддерживаются * , - /;
+//   - @yearly, @monthly, @weekly, @daily, @hourly;
+//   - @every <duration>, например "@every 5m".
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidSchedule, expr, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("%w %q: interval less than 1s", ErrInvalidSchedule, expr)
+		}
+		return Every(d), nil
+	}
+
+	if spec, ok := descriptors[expr]; ok {
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields", ErrInvalidSchedule, expr)
+	}
+
+	var c cron
+	var err error
+	for n, b := range bounds {
+		if c.fields[n], err = parseField(fields[n], b); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidSchedule, expr, err)
+		}
+	}
+	// воскресенье может быть задано как 7
+	if c.fields[dow]&(1<<7) != 0 {
+		c.fields[dow] |= 1
+	}
+	c.domAny = unrestricted(fields[dom], c.fields[dom], 1, 31)
+	// 7 уже учтено как воскресенье
+	c.dowAny = unrestricted(fields[dow], c.fields[dow], 0, 6)
+	return &c, nil
+}
+
+// unrestricted поле дня начинается с * (*/1, */2) или покрывает все значения lo-hi (1-31, 0-6),
+// такое поле не ограничивает день и не участвует в правиле "любое из двух"
+func unrestricted(field string, bits uint64, lo, hi int) bool {
+	if strings.HasPrefix(field, "*") {
+		return true
+	}
+	all := uint64(1)<<(hi+1) - uint64(1)<<lo
+	return bits&all == all
+}
+
+// Every расписание с фиксированным интервалом
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+const (
+	minute = iota
+	hour
+	dom
+	month
+	dow
+)
+
+type bound struct {
+	name     string
+	min, max int
+}
+
+var bounds = [5]bound{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cron набор допустимых значений по каждому полю в виде битовой маски
+type cron struct {
+	fields [5]uint64
+	domAny bool
+	dowAny bool
+}
+
+func parseField(field string, b bound) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: bad step %q", b.name, stepStr)
+			}
+		}
+
+		lo, hi := b.min, b.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("%s: bad value %q", b.name, from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("%s: bad value %q", b.name, to)
+				}
+			} else if hasStep {
+				hi = b.max
+			}
+		}
+		if lo < b.min || hi > b.max || lo > hi {
+			return 0, fmt.Errorf("%s: %q out of range %d-%d", b.name, part, b.min, b.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *cron) has(field, v int) bool {
+	return c.fields[field]&(1<<v) != 0
+}
+
+// dayMatches по правилам cron: если ограничены оба поля дня, достаточно совпадения любого
+func (c *cron) dayMatches(t time.Time) bool {
+	domOk := c.has(dom, t.Day())
+	dowOk := c.has(dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return domOk && dowOk
+	}
+	return domOk || dowOk
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// расписание вроде "0 0 30 2 *" никогда не сработает, ограничиваем поиск
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.has(month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.has(hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.has(minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
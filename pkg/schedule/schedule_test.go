@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNext(t *testing.T) {
+	// пятница
+	from := time.Date(2025, 1, 10, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 10, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 10, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC)},
+		{"30 9-18/3 * * 1-5", time.Date(2025, 1, 10, 12, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * 1", time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// поле, покрывающее все дни, не ограничивает день
+		{"0 0 */1 * 1", time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-31 * 1", time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 0-6", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 10, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every 1ms", "@every x"} {
+		_, err := Parse(expr)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, expr)
+	}
+}
+
+func TestNeverFires(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
@@ -0,0 +1,108 @@
+package trace
+
+import (
+	"fmt"
+	"net/http"
+
+	"git.vepay.dev/knoknok/backend-platform/pkg/httproute"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const httpTracerName = "git.vepay.dev/knoknok/backend-platform/pkg/trace/http"
+
+// MiddlewareHTTP серверные спаны для net/http.
+// Контекст родителя берется из заголовков W3C traceparent/baggage, имя спана - метод и шаблон маршрута
+// из pkg/httproute ("GET /users/{id}"), ответы 5xx и паники помечаются ошибкой.
+// Запросы, для которых filter возвращает false, не трассируются, filter может быть nil
+func MiddlewareHTTP(filter func(*http.Request) bool) func(http.HandlerFunc) http.HandlerFunc {
+	// провайдер и пропагатор глобальные, WithTrace может инициализировать их после создания мидлвари
+	tracer := otel.Tracer(httpTracerName)
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if filter != nil && !filter(r) {
+				next(w, r)
+				return
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx = httproute.WithHolder(ctx)
+
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.URLScheme(scheme),
+					semconv.ServerAddress(r.Host),
+					semconv.ClientAddress(r.RemoteAddr),
+					semconv.UserAgentOriginal(r.UserAgent()),
+				),
+			)
+			rec := &spanRecorder{ResponseWriter: w}
+			r = r.WithContext(ctx)
+
+			defer func() {
+				// шаблон известен после маршрутизации
+				route := httproute.Get(ctx)
+				if route == "" {
+					route = httproute.PatternPath(r.Pattern)
+				}
+				if route != "" {
+					span.SetName(r.Method + " " + route)
+					span.SetAttributes(semconv.HTTPRoute(route))
+				}
+
+				if p := recover(); p != nil {
+					span.SetStatus(codes.Error, fmt.Sprint(p))
+					span.End()
+					panic(p)
+				}
+
+				status := rec.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+				if status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(status))
+				}
+				span.End()
+			}()
+
+			next(rec, r)
+		}
+	}
+}
+
+type spanRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *spanRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *spanRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap для http.ResponseController
+func (w *spanRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -37,6 +37,8 @@ type Workflow interface {
 	SendEvent(ctx context.Context, messageKey string, correlationKey string, variables map[string]interface{}) error
 	// Health проба компонента
 	Health(ctx context.Context) error
+	// Ready возвращает ErrWorkflowIsNotReady, пока клиент не подключился и не задеплоил BPMN
+	Ready(ctx context.Context) error
 	// Close Завершает работу компонента
 	Close(ctx context.Context) error
 }
@@ -129,6 +131,13 @@ func (svc *workflowService) Health(ctx context.Context) error {
 	return nil
 }
 
+func (svc *workflowService) Ready(context.Context) error {
+	if !svc.readyToWork.Load() {
+		return ErrWorkflowIsNotReady
+	}
+	return nil
+}
+
 func (svc *workflowService) Close(ctx context.Context) error {
 	if !svc.readyToWork.Load() {
 		return nil